@@ -17,6 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,6 +31,416 @@ import (
 type Port struct {
 	PortName   string `json:"portName"`
 	PortNumber int32  `json:"portNumber"`
+
+	// Protocol defaults to TCP.
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+
+	// AppProtocol tells how the port is routed by the Gateway API: HTTP ports
+	// get a path on the HTTPRoute, GRPC ports a rule on the GRPCRoute and TCP
+	// ports their own TCPRoute. TCP ports are not exposed by Ingresses.
+	// +kubebuilder:validation:Enum=http;grpc;tcp
+	// +kubebuilder:default=http
+	// +optional
+	AppProtocol AppProtocol `json:"appProtocol,omitempty"`
+
+	// NodePort fixes the node port of this port when the Service is of type
+	// NodePort or LoadBalancer. One is allocated by Kubernetes otherwise.
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// HealthCheckPath generates HTTP readiness and liveness probes against
+	// this port, unless spec.probes sets them.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	HealthCheckPath string `json:"healthCheckPath,omitempty"`
+}
+
+// AppProtocol is the application protocol spoken on a port.
+type AppProtocol string
+
+const (
+	AppProtocolHTTP AppProtocol = "http"
+	AppProtocolGRPC AppProtocol = "grpc"
+	AppProtocolTCP  AppProtocol = "tcp"
+)
+
+// ProbesSpec configures the probes of the container. The readiness of the
+// container is reported by the Ready condition.
+type ProbesSpec struct {
+	// +optional
+	Liveness *corev1.Probe `json:"liveness,omitempty"`
+	// +optional
+	Readiness *corev1.Probe `json:"readiness,omitempty"`
+	// +optional
+	Startup *corev1.Probe `json:"startup,omitempty"`
+}
+
+// Container is an init or sidecar container run next to the main one.
+type Container struct {
+	// Name must differ from the other containers of the Pod.
+	Name  string `json:"name"`
+	Image string `json:"image"`
+
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// Ports of sidecars are exposed along with spec.ports. Port names must be
+	// unique across all the containers.
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Resources defaults to the operator default requests and limits.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// SpreadMode spreads the replicas of a workload across a topology domain.
+// +kubebuilder:validation:Enum=zone;node
+type SpreadMode string
+
+const (
+	SpreadZone SpreadMode = "zone"
+	SpreadNode SpreadMode = "node"
+)
+
+// SchedulingSpec configures where the Pods are scheduled.
+type SchedulingSpec struct {
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// Spread generates a topology spread constraint balancing the replicas of
+	// Deployments and StatefulSets across zones or nodes, unless
+	// topologySpreadConstraints already covers that topology.
+	// +optional
+	Spread SpreadMode `json:"spread,omitempty"`
+}
+
+// ImageUpdateStrategy selects the images the main container is moved to when
+// the registry is polled.
+// +kubebuilder:validation:Enum=None;Patch;Minor;SemverRange;LatestDigest
+type ImageUpdateStrategy string
+
+const (
+	ImageUpdateNone ImageUpdateStrategy = "None"
+	// ImageUpdatePatch moves to the highest tag of the same minor version.
+	ImageUpdatePatch ImageUpdateStrategy = "Patch"
+	// ImageUpdateMinor moves to the highest tag of the same major version.
+	ImageUpdateMinor ImageUpdateStrategy = "Minor"
+	// ImageUpdateSemverRange moves to the highest tag within the range.
+	ImageUpdateSemverRange ImageUpdateStrategy = "SemverRange"
+	// ImageUpdateLatestDigest pins the tag to its digest and follows the
+	// pushes to it.
+	ImageUpdateLatestDigest ImageUpdateStrategy = "LatestDigest"
+)
+
+// ImageUpdatePolicy polls the registry for newer versions of imageName.
+// Updates are applied to the workload and recorded in the status, imageName
+// itself is left as is.
+type ImageUpdatePolicy struct {
+	// +kubebuilder:default=None
+	// +optional
+	Strategy ImageUpdateStrategy `json:"strategy,omitempty"`
+	// Range is the semver constraint of the SemverRange strategy, e.g.
+	// ">=1.2.0 <2.0.0".
+	// +optional
+	Range string `json:"range,omitempty"`
+	// Interval between two polls of the registry. Defaults to 5m.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// File is a configuration file mounted in the container.
+type File struct {
+	// Path is the absolute path of the file in the container.
+	// +kubebuilder:validation:Pattern=`^/.*[^/]$`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// ServiceType is the way the generated Service exposes the ports.
+// +kubebuilder:validation:Enum=ClusterIP;Headless;NodePort;LoadBalancer
+type ServiceType string
+
+const (
+	ServiceTypeClusterIP    ServiceType = "ClusterIP"
+	ServiceTypeHeadless     ServiceType = "Headless"
+	ServiceTypeNodePort     ServiceType = "NodePort"
+	ServiceTypeLoadBalancer ServiceType = "LoadBalancer"
+)
+
+// ServiceSpec configures the generated Service.
+type ServiceSpec struct {
+	// Type defaults to Headless for StatefulSet workloads, and to ClusterIP
+	// otherwise. The governing Service of a StatefulSet without ports is
+	// always headless.
+	// +optional
+	Type ServiceType `json:"type,omitempty"`
+
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerClass is only used by LoadBalancer Services.
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+	// LoadBalancerSourceRanges is only used by LoadBalancer Services.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy is only used by NodePort and LoadBalancer Services.
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// +kubebuilder:validation:Enum=None;ClientIP
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+}
+
+// IngressSpec configures the generated Ingress.
+type IngressSpec struct {
+	// Hosts the ports are exposed on. The operator default host template is
+	// used when empty.
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Annotations are added to the generated Ingress, and take precedence over
+	// the ones set by the operator.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// +kubebuilder:validation:Enum=Exact;Prefix;ImplementationSpecific
+	// +kubebuilder:default=Prefix
+	// +optional
+	PathType networkingv1.PathType `json:"pathType,omitempty"`
+
+	// Rewrite makes the nginx ingress controller strip the port path before
+	// forwarding requests. Enabled by default.
+	// +optional
+	Rewrite *bool `json:"rewrite,omitempty"`
+
+	// +optional
+	TLS *IngressTLS `json:"tls,omitempty"`
+}
+
+// IngressTLS serves the Ingress hosts over HTTPS, either with an existing
+// certificate or with one requested to cert-manager.
+type IngressTLS struct {
+	// SecretName is the Secret holding the certificate. It defaults to
+	// <name>-tls, which is where cert-manager stores the requested certificate.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Issuer is the cert-manager Issuer requesting the certificate.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+	// ClusterIssuer is the cert-manager ClusterIssuer requesting the
+	// certificate.
+	// +optional
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+// ExposureMode selects how the ports are exposed outside of the cluster.
+// +kubebuilder:validation:Enum=Ingress;Gateway;None
+type ExposureMode string
+
+const (
+	ExposureIngress ExposureMode = "Ingress"
+	ExposureGateway ExposureMode = "Gateway"
+	ExposureNone    ExposureMode = "None"
+)
+
+// GatewayParentRef is the Gateway the generated routes are attached to.
+type GatewayParentRef struct {
+	Name string `json:"name"`
+	// Namespace defaults to the namespace of the PodInstanciator.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// SectionName attaches the HTTP and GRPC routes to a single listener.
+	// TCP routes are always attached to the listener named after their port.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// GatewaySpec configures the generated Gateway API routes.
+type GatewaySpec struct {
+	// ParentRef defaults to the operator default Gateway.
+	// +optional
+	ParentRef *GatewayParentRef `json:"parentRef,omitempty"`
+
+	// Hostnames of the HTTP and GRPC routes. The operator default host
+	// template is used when empty.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Rewrite strips the port path before forwarding HTTP requests. It relies
+	// on the URLRewrite filter of the experimental channel.
+	// +optional
+	Rewrite bool `json:"rewrite,omitempty"`
+}
+
+// WorkloadKind is the kind of the resource running the container.
+// +kubebuilder:validation:Enum=Deployment;StatefulSet;Pod;Job;CronJob
+type WorkloadKind string
+
+const (
+	WorkloadDeployment WorkloadKind = "Deployment"
+	// WorkloadStatefulSet gives each replica a stable hostname through the
+	// generated Service, which is headless by default, and its own volumes.
+	WorkloadStatefulSet WorkloadKind = "StatefulSet"
+	// WorkloadPod runs a bare Pod, which is not rescheduled when its node
+	// goes away. It is meant for throwaway sandboxes.
+	WorkloadPod WorkloadKind = "Pod"
+	// WorkloadJob runs the container until it succeeds. Changing the spec of
+	// the instance runs the Job again.
+	WorkloadJob WorkloadKind = "Job"
+	// WorkloadCronJob runs a Job on a schedule.
+	WorkloadCronJob WorkloadKind = "CronJob"
+)
+
+// DeletionPolicy tells what happens to persistent data when the resources
+// using it are deleted.
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+// ClaimSpec describes a PersistentVolumeClaim generated by the operator.
+type ClaimSpec struct {
+	Size resource.Quantity `json:"size"`
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// AccessModes defaults to ReadWriteOnce.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// VolumeClaimTemplate declares a volume claimed for each replica of a
+// StatefulSet.
+type VolumeClaimTemplate struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+
+	ClaimSpec `json:",inline"`
+}
+
+// GeneratedClaim is a PersistentVolumeClaim generated for a volume and shared
+// by all the replicas. Without a ReadWriteMany or ReadOnlyMany access mode, it
+// only allows a single replica, and a Deployment mounting it is updated by
+// recreating its Pods.
+type GeneratedClaim struct {
+	ClaimSpec `json:",inline"`
+
+	// DeletionPolicy tells whether the claim is deleted along with the
+	// PodInstanciator, or when the volume is removed. Defaults to Delete.
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+}
+
+// Volume is a volume of the Pod, mounted through spec.volumeMounts. Exactly
+// one source has to be set.
+type Volume struct {
+	Name string `json:"name"`
+
+	// +optional
+	EmptyDir *corev1.EmptyDirVolumeSource `json:"emptyDir,omitempty"`
+	// PersistentVolumeClaim mounts an existing claim.
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+	// GeneratedClaim mounts a claim generated by the operator.
+	// +optional
+	GeneratedClaim *GeneratedClaim `json:"generatedClaim,omitempty"`
+	// +optional
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+	// +optional
+	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+}
+
+// WorkloadSpec configures the resource running the container.
+type WorkloadSpec struct {
+	// +kubebuilder:default=Deployment
+	// +optional
+	Kind WorkloadKind `json:"kind,omitempty"`
+
+	// Replicas defaults to 1. Only used by replicated workloads. A Deployment
+	// without replicas leaves them to an autoscaler.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// RollingUpdate configures the rolling updates of a Deployment. It is
+	// ignored when a generated claim restricts the Pods to a single node.
+	// +optional
+	RollingUpdate *appsv1.RollingUpdateDeployment `json:"rollingUpdate,omitempty"`
+
+	// RevisionHistoryLimit is the number of old revisions kept to allow a
+	// rollback of a Deployment or a StatefulSet.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// VolumeClaimTemplates are the volumes of each replica of a StatefulSet.
+	// +optional
+	VolumeClaimTemplates []VolumeClaimTemplate `json:"volumeClaimTemplates,omitempty"`
+	// PodManagementPolicy tells whether the replicas of a StatefulSet are
+	// started and stopped one after the other or in parallel.
+	// +kubebuilder:validation:Enum=OrderedReady;Parallel
+	// +optional
+	PodManagementPolicy appsv1.PodManagementPolicyType `json:"podManagementPolicy,omitempty"`
+	// VolumeClaimDeletionPolicy tells whether the volumes of a StatefulSet are
+	// deleted along with the PodInstanciator. Defaults to Retain. Clusters
+	// older than 1.27 need the StatefulSetAutoDeletePVC feature gate.
+	// +optional
+	VolumeClaimDeletionPolicy DeletionPolicy `json:"volumeClaimDeletionPolicy,omitempty"`
+
+	// Completions is the number of Pods of a Job which have to succeed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Completions *int32 `json:"completions,omitempty"`
+	// Parallelism is the number of Pods of a Job running at once.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Parallelism *int32 `json:"parallelism,omitempty"`
+	// BackoffLimit is the number of retries of a Job before it is failed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// ActiveDeadlineSeconds is how long a Job may run before it is failed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// Schedule is the cron schedule of a CronJob, which requires it.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// ConcurrencyPolicy tells what happens when a CronJob is due while its
+	// previous Job still runs. Defaults to Allow.
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +optional
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
 }
 
 // PodInstanciatorSpec defines the desired state of PodInstanciator
@@ -34,17 +449,224 @@ type PodInstanciatorSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	ImageName string `json:"imageName"`
-	Ports     []Port `json:"ports"`
+	// PinImageDigest resolves the tag of the image to a digest, which the main
+	// container is pinned to until imageName changes or the image is updated.
+	// +optional
+	PinImageDigest bool `json:"pinImageDigest,omitempty"`
+	// +optional
+	ImageUpdatePolicy ImageUpdatePolicy `json:"imageUpdatePolicy,omitempty"`
+	// ImagePullPolicy applies to all the containers.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets are used along with the operator default pull secret.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Command replaces the entrypoint of the image.
+	// +optional
+	Command []string `json:"command,omitempty"`
+	// Args replaces the command of the image.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// +optional
+	WorkingDir string `json:"workingDir,omitempty"`
+	// Lifecycle sets the postStart and preStop hooks of the container.
+	// +optional
+	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
+	// TerminationGracePeriodSeconds is how long the container is given to stop
+	// after the preStop hook and the termination signal.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// Ports are exposed by the Service and the exposure resources, which are
+	// not created when there are none.
+	// +optional
+	Ports []Port `json:"ports,omitempty"`
+
+	// Env sets environment variables of the container from literal values,
+	// or from keys of ConfigMaps, Secrets and fields of the Pod.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// EnvFrom sets environment variables from all the keys of ConfigMaps and
+	// Secrets.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// Files are rendered into a generated ConfigMap and mounted in the
+	// container at their path.
+	// +optional
+	Files []File `json:"files,omitempty"`
+	// +optional
+	Probes ProbesSpec `json:"probes,omitempty"`
+
+	// InitContainers run one after the other before the containers start.
+	// +optional
+	InitContainers []Container `json:"initContainers,omitempty"`
+	// Sidecars run next to the main container.
+	// +optional
+	Sidecars []Container `json:"sidecars,omitempty"`
+
+	// Resources defaults to the operator default requests and limits. Requests
+	// cannot exceed limits.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// +optional
+	Volumes []Volume `json:"volumes,omitempty"`
+	// +optional
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// +optional
+	Workload WorkloadSpec `json:"workload,omitempty"`
+	// +optional
+	Scheduling SchedulingSpec `json:"scheduling,omitempty"`
+
+	// +optional
+	Service ServiceSpec `json:"service,omitempty"`
+	// Exposure defaults to the operator default exposure mode.
+	// +optional
+	Exposure ExposureMode `json:"exposure,omitempty"`
+	// +optional
+	Ingress IngressSpec `json:"ingress,omitempty"`
+	// +optional
+	Gateway GatewaySpec `json:"gateway,omitempty"`
+}
+
+const (
+	// ConditionReady is set when every generated resource is ready.
+	ConditionReady = "Ready"
+	// ConditionPodReady is set when the Pods of the workload are ready.
+	ConditionPodReady = "PodReady"
+	// ConditionServiceReady is set when the generated Service has ready
+	// endpoints.
+	ConditionServiceReady = "ServiceReady"
+	// ConditionIngressReady is set when the generated Ingress got an address.
+	ConditionIngressReady = "IngressReady"
+	// ConditionRouteReady is set when every generated Gateway API route is
+	// accepted by its Gateway.
+	ConditionRouteReady = "RouteReady"
+	// ConditionDegraded is set when the generated resources could not be
+	// brought to the desired state.
+	ConditionDegraded = "Degraded"
+)
+
+// PodInstanciatorPhase summarizes the conditions of a PodInstanciator.
+type PodInstanciatorPhase string
+
+const (
+	PhasePending PodInstanciatorPhase = "Pending"
+	PhaseRunning PodInstanciatorPhase = "Running"
+	// PhaseSucceeded is only reached by Job workloads.
+	PhaseSucceeded PodInstanciatorPhase = "Succeeded"
+	PhaseFailed    PodInstanciatorPhase = "Failed"
+	PhaseDegraded  PodInstanciatorPhase = "Degraded"
+)
+
+// PortURL is the external URL under which a port is exposed.
+type PortURL struct {
+	PortName string `json:"portName"`
+	URL      string `json:"url"`
+}
+
+// TLSStatus describes the certificate served by the Ingress.
+type TLSStatus struct {
+	SecretName   string `json:"secretName"`
+	SecretExists bool   `json:"secretExists"`
+	// NotAfter is the expiration date of the certificate.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+}
+
+// ImageUpdate records a change of the image run by the main container.
+type ImageUpdate struct {
+	From string      `json:"from"`
+	To   string      `json:"to"`
+	Time metav1.Time `json:"time"`
 }
 
 // PodInstanciatorStatus defines the observed state of PodInstanciator
 type PodInstanciatorStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	Phase PodInstanciatorPhase `json:"phase,omitempty"`
+
+	// Image is the image run by the main container, pinned to its digest
+	// when spec.pinImageDigest is set.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// ImageDigest is the digest Image was resolved to.
+	// +optional
+	ImageDigest string `json:"imageDigest,omitempty"`
+	// ImageSource is the imageName Image derives from. Image starts over from
+	// imageName when it changes.
+	// +optional
+	ImageSource string `json:"imageSource,omitempty"`
+	// +optional
+	LastImagePollTime *metav1.Time `json:"lastImagePollTime,omitempty"`
+	// ImageHistory lists the last updates of Image, the latest last.
+	// +optional
+	ImageHistory []ImageUpdate `json:"imageHistory,omitempty"`
+
+	// +optional
+	WorkloadName string `json:"workloadName,omitempty"`
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// ConfigHash is the hash of the referenced ConfigMaps and Secrets the
+	// workload currently runs with.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+	// PodName is only set for bare Pod workloads.
+	// +optional
+	PodName string `json:"podName,omitempty"`
+
+	// Active, Succeeded and Failed count the Pods of a Job, or the Jobs of a
+	// CronJob still in its history.
+	// +optional
+	Active int32 `json:"active,omitempty"`
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+	// LastCompletionTime is when the last Job succeeded.
+	// +optional
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// +optional
+	IngressName string `json:"ingressName,omitempty"`
+	// +optional
+	RouteNames []string `json:"routeNames,omitempty"`
+
+	// PodIP is only set for bare Pod workloads.
+	// +optional
+	PodIP string `json:"podIP,omitempty"`
+	// +optional
+	URLs []PortURL `json:"urls,omitempty"`
+	// +optional
+	TLS *TLSStatus `json:"tls,omitempty"`
+
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=pi,categories=all
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.imageName`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.urls[0].url`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // PodInstanciator is the Schema for the podinstanciators API
 type PodInstanciator struct {
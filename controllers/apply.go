@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// fieldManager owns the fields rendered by the operator on the generated
+// resources, leaving the other ones to the tools that set them.
+const fieldManager = "podinstanciator"
+
+// recreateFunc reports whether the found resource cannot be patched into the
+// desired one and has to be deleted first.
+type recreateFunc func(resource client.Object, foundResource client.Object) bool
+
+// generatedResource is a resource rendered from a PodInstanciator. Retained
+// resources are not owned by the instance, so they are neither garbage
+// collected with it nor deleted once they are not rendered anymore. The
+// dependents of a resource orphaned on recreate are kept, so that the new
+// resource adopts them.
+type generatedResource struct {
+	resource         client.Object
+	foundResource    client.Object
+	needsRecreate    recreateFunc
+	retain           bool
+	orphanOnRecreate bool
+}
+
+// ownedResourceLists lists the kinds of the resources which can be generated,
+// so that the ones which are not rendered anymore get deleted.
+func ownedResourceLists() []client.ObjectList {
+	return []client.ObjectList{
+		&corev1.PodList{},
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&batchv1.JobList{},
+		&batchv1.CronJobList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.PersistentVolumeClaimList{},
+		&networkingv1.IngressList{},
+		&gatewayv1beta1.HTTPRouteList{},
+		&gatewayv1alpha2.GRPCRouteList{},
+		&gatewayv1alpha2.TCPRouteList{},
+	}
+}
+
+// deletePropagation deletes the dependents of the deleted resources, which
+// Jobs would orphan by default.
+var deletePropagation = client.PropagationPolicy(metav1.DeletePropagationBackground)
+
+// applyResource server-side applies the generated resource. When needsRecreate
+// is set, the existing resource is deleted instead if it cannot be patched, and
+// true is returned so the caller reconciles again once it is gone. Rendered
+// fields owned by another field manager, such as the one which created the
+// resources before they were applied, are taken over, and the conflict is
+// still returned so that it gets reported.
+func applyResource(r *PodInstanciatorReconciler, ctx context.Context, generated generatedResource) (bool, error) {
+	resource, foundResource := generated.resource, generated.foundResource
+	if generated.needsRecreate != nil {
+		err := r.Get(ctx, types.NamespacedName{Name: resource.GetName(), Namespace: resource.GetNamespace()}, foundResource)
+		if err == nil {
+			if foundResource.GetDeletionTimestamp() != nil {
+				return true, nil
+			}
+			if generated.needsRecreate(resource, foundResource) {
+				propagation := deletePropagation
+				if generated.orphanOnRecreate {
+					propagation = client.PropagationPolicy(metav1.DeletePropagationOrphan)
+				}
+				return true, client.IgnoreNotFound(r.Delete(ctx, foundResource, propagation))
+			}
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	err := r.Patch(ctx, resource, client.Apply, client.FieldOwner(fieldManager))
+	if !errors.IsConflict(err) {
+		return false, err
+	}
+	if err := r.Patch(ctx, resource, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+		return false, err
+	}
+	return false, err
+}
+
+// deleteUnwantedResources deletes the resources of the kind of list which are
+// controlled by the instance but are not part of the generated resources.
+// They are selected by owner rather than by label, since the resources of the
+// previous versions, such as the <name>-pod Pod, do not carry the labels.
+// Kinds whose CRDs are not installed are skipped.
+func deleteUnwantedResources(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator, list client.ObjectList, resources []generatedResource) error {
+	err := r.List(ctx, list, client.InNamespace(instance.Namespace))
+	if meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return meta.EachListItem(list, func(item runtime.Object) error {
+		found := item.(client.Object)
+		if !metav1.IsControlledBy(found, instance) {
+			return nil
+		}
+		for _, generated := range resources {
+			if generated.resource.GetName() == found.GetName() {
+				return nil
+			}
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, found, deletePropagation))
+	})
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+var _ = Describe("deleteUnwantedResources", func() {
+	var (
+		ctx      context.Context
+		instance *apiv1alpha1.PodInstanciator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		instance = &apiv1alpha1.PodInstanciator{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "app-uid"},
+			Spec:       apiv1alpha1.PodInstanciatorSpec{ImageName: "nginx"},
+		}
+	})
+
+	newPod := func(name string, owner *apiv1alpha1.PodInstanciator, labels map[string]string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+		if owner != nil {
+			Expect(controllerutil.SetControllerReference(owner, pod, scheme.Scheme)).To(Succeed())
+		}
+		return pod
+	}
+
+	It("deletes the unlabeled Pod of the previous version", func() {
+		legacy := newPod(getPodName(instance), instance, nil)
+		unowned := newPod("unowned", nil, getSelectorLabels(instance))
+		other := &apiv1alpha1.PodInstanciator{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other-uid"}}
+		foreign := newPod("other-pod", other, nil)
+		r := &PodInstanciatorReconciler{
+			Client: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(legacy, unowned, foreign).Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		Expect(deleteUnwantedResources(r, ctx, instance, &corev1.PodList{}, nil)).To(Succeed())
+
+		err := r.Get(ctx, types.NamespacedName{Name: legacy.Name, Namespace: "default"}, &corev1.Pod{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(r.Get(ctx, types.NamespacedName{Name: "unowned", Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+		Expect(r.Get(ctx, types.NamespacedName{Name: "other-pod", Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+	})
+
+	It("keeps the generated resources", func() {
+		pod := newPod(getPodName(instance), instance, getSelectorLabels(instance))
+		r := &PodInstanciatorReconciler{
+			Client: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod).Build(),
+			Scheme: scheme.Scheme,
+		}
+
+		generated := []generatedResource{{resource: newPod(getPodName(instance), nil, nil), foundResource: &corev1.Pod{}}}
+		Expect(deleteUnwantedResources(r, ctx, instance, &corev1.PodList{}, generated)).To(Succeed())
+		Expect(r.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: "default"}, &corev1.Pod{})).To(Succeed())
+	})
+})
+
+var _ = Describe("applyResource", func() {
+	newIngress := func(host string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: networkingv1.SchemeGroupVersion.String(), Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "default"},
+			Spec:       networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: host}}},
+		}
+	}
+
+	It("takes over the resources created before they were applied", func() {
+		ctx := context.Background()
+		r := &PodInstanciatorReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+		created := newIngress("a.example.com")
+		Expect(k8sClient.Create(ctx, created)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, created)).To(Succeed())
+		})
+
+		_, err := applyResource(r, ctx, generatedResource{resource: newIngress("b.example.com"), foundResource: &networkingv1.Ingress{}})
+		Expect(errors.IsConflict(err)).To(BeTrue())
+		found := &networkingv1.Ingress{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "created", Namespace: "default"}, found)).To(Succeed())
+		Expect(found.Spec.Rules).To(HaveLen(1))
+		Expect(found.Spec.Rules[0].Host).To(Equal("b.example.com"))
+
+		_, err = applyResource(r, ctx, generatedResource{resource: newIngress("c.example.com"), foundResource: &networkingv1.Ingress{}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "created", Namespace: "default"}, found)).To(Succeed())
+		Expect(found.Spec.Rules[0].Host).To(Equal("c.example.com"))
+	})
+})
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// configHashAnnotation is set on the Pod template so that the workload rolls
+// out when the content of the referenced ConfigMaps and Secrets changes.
+const configHashAnnotation = "api.my.domain/config-hash"
+
+func uniqueSorted(names []string) []string {
+	sort.Strings(names)
+	unique := names[:0]
+	for i, name := range names {
+		if i == 0 || name != names[i-1] {
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// getEnvSources returns the environment variables and sources of all the
+// containers.
+func getEnvSources(instance *apiv1alpha1.PodInstanciator) ([]corev1.EnvVar, []corev1.EnvFromSource) {
+	env := append([]corev1.EnvVar{}, instance.Spec.Env...)
+	envFrom := append([]corev1.EnvFromSource{}, instance.Spec.EnvFrom...)
+	containers := append(append([]apiv1alpha1.Container{}, instance.Spec.InitContainers...), instance.Spec.Sidecars...)
+	for _, container := range containers {
+		env = append(env, container.Env...)
+		envFrom = append(envFrom, container.EnvFrom...)
+	}
+	return env, envFrom
+}
+
+// getConfigReferences returns the names of the ConfigMaps and of the Secrets
+// the containers read their configuration from.
+func getConfigReferences(instance *apiv1alpha1.PodInstanciator) ([]string, []string) {
+	var configMaps, secrets []string
+	envVars, envSources := getEnvSources(instance)
+	for _, env := range envVars {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			configMaps = append(configMaps, ref.Name)
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			secrets = append(secrets, ref.Name)
+		}
+	}
+	for _, env := range envSources {
+		if env.ConfigMapRef != nil {
+			configMaps = append(configMaps, env.ConfigMapRef.Name)
+		}
+		if env.SecretRef != nil {
+			secrets = append(secrets, env.SecretRef.Name)
+		}
+	}
+	for _, volume := range instance.Spec.Volumes {
+		if volume.ConfigMap != nil {
+			configMaps = append(configMaps, volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			secrets = append(secrets, volume.Secret.SecretName)
+		}
+	}
+	return uniqueSorted(configMaps), uniqueSorted(secrets)
+}
+
+// computeConfigHash hashes the content of the files and of the referenced
+// ConfigMaps and Secrets. Missing ones are hashed too, so that creating them
+// triggers a rollout. It returns an empty hash when there is no configuration.
+func computeConfigHash(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (string, error) {
+	configMaps, secrets := getConfigReferences(instance)
+	if len(configMaps) == 0 && len(secrets) == 0 && len(instance.Spec.Files) == 0 {
+		return "", nil
+	}
+	content := map[string]interface{}{}
+	if len(instance.Spec.Files) > 0 {
+		content["files"] = instance.Spec.Files
+	}
+	for _, name := range configMaps {
+		configMap := &corev1.ConfigMap{}
+		found, err := getResource(r, ctx, name, instance.Namespace, configMap)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			content["configmap/"+name] = []interface{}{configMap.Data, configMap.BinaryData}
+		} else {
+			content["configmap/"+name] = nil
+		}
+	}
+	for _, name := range secrets {
+		secret := &corev1.Secret{}
+		found, err := getResource(r, ctx, name, instance.Namespace, secret)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			content["secret/"+name] = secret.Data
+		} else {
+			content["secret/"+name] = nil
+		}
+	}
+	return computeHash(content), nil
+}
+
+func setConfigHash(annotations map[string]string, configHash string) map[string]string {
+	if configHash == "" {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[configHashAnnotation] = configHash
+	return annotations
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func getReplicas(instance *apiv1alpha1.PodInstanciator) int32 {
+	if instance.Spec.Workload.Replicas == nil {
+		return 1
+	}
+	return *instance.Spec.Workload.Replicas
+}
+
+// getDeploymentStrategy rolls the updates out, unless a generated claim can
+// only be mounted on a single node, in which case the old Pods have to release
+// it before the new ones start.
+func getDeploymentStrategy(instance *apiv1alpha1.PodInstanciator) appsv1.DeploymentStrategy {
+	if hasSingleNodeClaim(instance) {
+		return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	}
+	return appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: instance.Spec.Workload.RollingUpdate,
+	}
+}
+
+// createDeployment renders the Deployment of the instance. The replicas are
+// only set when declared, so that an autoscaler can manage them otherwise.
+func createDeployment(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getDeploymentName(instance),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: instance.Spec.Workload.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getSelectorLabels(instance),
+			},
+			Template:             template,
+			Strategy:             getDeploymentStrategy(instance),
+			RevisionHistoryLimit: instance.Spec.Workload.RevisionHistoryLimit,
+		},
+	}
+}
+
+// deploymentNeedsRecreate reports whether the Deployment switches to the
+// Recreate strategy while it still has the rolling update parameters the API
+// server defaulted. They are owned by no field manager, so the apply cannot
+// drop them, and they are refused along with the Recreate strategy. The Pods
+// are replaced by the new Deployment anyway, as the Recreate strategy would.
+func deploymentNeedsRecreate(resource client.Object, foundResource client.Object) bool {
+	deployment := resource.(*appsv1.Deployment)
+	found := foundResource.(*appsv1.Deployment)
+	return deployment.Spec.Strategy.Type == appsv1.RecreateDeploymentStrategyType && found.Spec.Strategy.RollingUpdate != nil
+}
+
+func getDeploymentReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
+
+func isDeploymentReady(deployment *appsv1.Deployment) bool {
+	replicas := getDeploymentReplicas(deployment)
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.ReadyReplicas >= replicas
+}
+
+func isDeploymentFailed(deployment *appsv1.Deployment) bool {
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing {
+			return condition.Status == corev1.ConditionFalse && condition.Reason == "ProgressDeadlineExceeded"
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+var _ = Describe("Deployment strategy", func() {
+	var (
+		ctx      context.Context
+		r        *PodInstanciatorReconciler
+		instance *apiv1alpha1.PodInstanciator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		r = &PodInstanciatorReconciler{Client: k8sClient, Scheme: scheme.Scheme}
+		instance = &apiv1alpha1.PodInstanciator{
+			ObjectMeta: metav1.ObjectMeta{Name: "strategy", Namespace: "default"},
+			Spec:       apiv1alpha1.PodInstanciatorSpec{ImageName: "nginx"},
+		}
+	})
+
+	applyDeployment := func(recreate bool) *appsv1.Deployment {
+		resources := createWorkloadResources(instance, createPodTemplate(r, instance))
+		recreated, err := applyResource(r, ctx, resources[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recreated).To(Equal(recreate))
+		if recreated {
+			recreated, err = applyResource(r, ctx, resources[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recreated).To(BeFalse())
+		}
+		deployment := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: getDeploymentName(instance), Namespace: "default"}, deployment)).To(Succeed())
+		return deployment
+	}
+
+	It("is recreated to switch to Recreate once a single-node claim is mounted", func() {
+		deployment := applyDeployment(false)
+		DeferCleanup(func() {
+			Expect(k8sClient.Delete(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: deployment.Name, Namespace: deployment.Namespace}})).To(Succeed())
+		})
+		Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		Expect(deployment.Spec.Strategy.RollingUpdate).NotTo(BeNil())
+
+		instance.Spec.Volumes = []apiv1alpha1.Volume{{
+			Name:           "data",
+			GeneratedClaim: &apiv1alpha1.GeneratedClaim{ClaimSpec: apiv1alpha1.ClaimSpec{Size: resource.MustParse("1Gi")}},
+		}}
+		deployment = applyDeployment(true)
+		Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+		Expect(deployment.Spec.Strategy.RollingUpdate).To(BeNil())
+
+		instance.Spec.Volumes = nil
+		deployment = applyDeployment(false)
+		Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+	})
+})
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// createExposureResources renders the resources exposing the ports outside of
+// the cluster, according to the exposure mode of the instance.
+func createExposureResources(r *PodInstanciatorReconciler, instance *apiv1alpha1.PodInstanciator) ([]generatedResource, error) {
+	if !hasPorts(instance) {
+		return nil, nil
+	}
+	switch getExposureMode(instance, r.DefaultExposure) {
+	case apiv1alpha1.ExposureIngress:
+		hosts, err := getIngressHosts(instance, r.IngressHostTemplate)
+		if err != nil {
+			return nil, err
+		}
+		return []generatedResource{
+			{resource: createIngress(instance, hosts), foundResource: &networkingv1.Ingress{}},
+		}, nil
+	case apiv1alpha1.ExposureGateway:
+		parent, err := getGatewayParentRef(instance, r.DefaultGateway)
+		if err != nil {
+			return nil, err
+		}
+		hosts, err := getGatewayHostnames(instance, r.IngressHostTemplate)
+		if err != nil {
+			return nil, err
+		}
+		var resources []generatedResource
+		if len(getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolHTTP)) > 0 {
+			resources = append(resources, generatedResource{resource: createHTTPRoute(instance, parent, hosts), foundResource: &gatewayv1beta1.HTTPRoute{}})
+		}
+		if len(getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolGRPC)) > 0 {
+			resources = append(resources, generatedResource{resource: createGRPCRoute(instance, parent, hosts), foundResource: &gatewayv1alpha2.GRPCRoute{}})
+		}
+		for _, port := range getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolTCP) {
+			resources = append(resources, generatedResource{resource: createTCPRoute(instance, parent, port), foundResource: &gatewayv1alpha2.TCPRoute{}})
+		}
+		return resources, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+const filesVolumeName = "podinstanciator-files"
+
+// getFileKey returns the ConfigMap key of the file at index i. Keys are not
+// derived from the paths, which may contain characters keys do not allow.
+func getFileKey(i int) string {
+	return fmt.Sprintf("file-%d", i)
+}
+
+// validateFiles checks that no two files are mounted at the same path.
+func validateFiles(instance *apiv1alpha1.PodInstanciator) error {
+	paths := map[string]bool{}
+	for _, file := range instance.Spec.Files {
+		if paths[file.Path] {
+			return fmt.Errorf("file path %s is declared more than once", file.Path)
+		}
+		paths[file.Path] = true
+	}
+	return nil
+}
+
+func createConfigMap(instance *apiv1alpha1.PodInstanciator) *corev1.ConfigMap {
+	data := make(map[string]string, len(instance.Spec.Files))
+	for i, file := range instance.Spec.Files {
+		data[getFileKey(i)] = file.Content
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getConfigMapName(instance),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Data: data,
+	}
+}
+
+func createFilesVolumes(instance *apiv1alpha1.PodInstanciator) []corev1.Volume {
+	if len(instance.Spec.Files) == 0 {
+		return nil
+	}
+	return []corev1.Volume{
+		{
+			Name: filesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: getConfigMapName(instance)},
+				},
+			},
+		},
+	}
+}
+
+// createFilesVolumeMounts mounts each file on its own, leaving the rest of its
+// directory untouched. Such mounts are not refreshed by the kubelet, the
+// content of the files is part of the configuration hash instead.
+func createFilesVolumeMounts(instance *apiv1alpha1.PodInstanciator) []corev1.VolumeMount {
+	mounts := make([]corev1.VolumeMount, len(instance.Spec.Files))
+	for i, file := range instance.Spec.Files {
+		mounts[i] = corev1.VolumeMount{
+			Name:      filesVolumeName,
+			MountPath: file.Path,
+			SubPath:   getFileKey(i),
+			ReadOnly:  true,
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"fmt"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func getExposureMode(instance *apiv1alpha1.PodInstanciator, defaultMode apiv1alpha1.ExposureMode) apiv1alpha1.ExposureMode {
+	if instance.Spec.Exposure != "" {
+		return instance.Spec.Exposure
+	}
+	if defaultMode != "" {
+		return defaultMode
+	}
+	return apiv1alpha1.ExposureIngress
+}
+
+func getGatewayParentRef(instance *apiv1alpha1.PodInstanciator, defaultGateway *apiv1alpha1.GatewayParentRef) (*apiv1alpha1.GatewayParentRef, error) {
+	parent := instance.Spec.Gateway.ParentRef
+	if parent == nil {
+		parent = defaultGateway
+	}
+	if parent == nil || parent.Name == "" {
+		return nil, fmt.Errorf("no Gateway to attach the routes of %s/%s to", instance.Namespace, instance.Name)
+	}
+	return parent, nil
+}
+
+func getGatewayHostnames(instance *apiv1alpha1.PodInstanciator, hostTemplate *template.Template) ([]string, error) {
+	if len(instance.Spec.Gateway.Hostnames) > 0 {
+		return instance.Spec.Gateway.Hostnames, nil
+	}
+	return getDefaultHosts(instance, hostTemplate)
+}
+
+func createParentReference(instance *apiv1alpha1.PodInstanciator, parent *apiv1alpha1.GatewayParentRef, sectionName string) gatewayv1beta1.ParentReference {
+	namespace := gatewayv1beta1.Namespace(instance.Namespace)
+	if parent.Namespace != "" {
+		namespace = gatewayv1beta1.Namespace(parent.Namespace)
+	}
+	reference := gatewayv1beta1.ParentReference{
+		Name:      gatewayv1beta1.ObjectName(parent.Name),
+		Namespace: &namespace,
+	}
+	if sectionName != "" {
+		section := gatewayv1beta1.SectionName(sectionName)
+		reference.SectionName = &section
+	}
+	return reference
+}
+
+func createBackendRef(instance *apiv1alpha1.PodInstanciator, port apiv1alpha1.Port) gatewayv1beta1.BackendRef {
+	portNumber := gatewayv1beta1.PortNumber(port.PortNumber)
+	return gatewayv1beta1.BackendRef{
+		BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+			Name: gatewayv1beta1.ObjectName(getServiceName(instance)),
+			Port: &portNumber,
+		},
+	}
+}
+
+func createHostnames(hosts []string) []gatewayv1beta1.Hostname {
+	var hostnames []gatewayv1beta1.Hostname
+	for _, host := range hosts {
+		if host != "" {
+			hostnames = append(hostnames, gatewayv1beta1.Hostname(host))
+		}
+	}
+	return hostnames
+}
+
+func createHTTPRouteRules(instance *apiv1alpha1.PodInstanciator) []gatewayv1beta1.HTTPRouteRule {
+	ports := getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolHTTP)
+	rules := make([]gatewayv1beta1.HTTPRouteRule, len(ports))
+	pathType := gatewayv1beta1.PathMatchPathPrefix
+	for i, port := range ports {
+		path := getIngressPathName(port)
+		rules[i] = gatewayv1beta1.HTTPRouteRule{
+			Matches: []gatewayv1beta1.HTTPRouteMatch{
+				{
+					Path: &gatewayv1beta1.HTTPPathMatch{
+						Type:  &pathType,
+						Value: &path,
+					},
+				},
+			},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+				{BackendRef: createBackendRef(instance, port)},
+			},
+		}
+		if instance.Spec.Gateway.Rewrite {
+			replacement := "/"
+			rules[i].Filters = []gatewayv1beta1.HTTPRouteFilter{
+				{
+					Type: gatewayv1beta1.HTTPRouteFilterURLRewrite,
+					URLRewrite: &gatewayv1beta1.HTTPURLRewriteFilter{
+						Path: &gatewayv1beta1.HTTPPathModifier{
+							Type:               gatewayv1beta1.PrefixMatchHTTPPathModifier,
+							ReplacePrefixMatch: &replacement,
+						},
+					},
+				},
+			}
+		}
+	}
+	return rules
+}
+
+func createHTTPRoute(instance *apiv1alpha1.PodInstanciator, parent *apiv1alpha1.GatewayParentRef, hosts []string) *gatewayv1beta1.HTTPRoute {
+	return &gatewayv1beta1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1beta1.GroupVersion.String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getHTTPRouteName(instance),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: gatewayv1beta1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+				ParentRefs: []gatewayv1beta1.ParentReference{
+					createParentReference(instance, parent, parent.SectionName),
+				},
+			},
+			Hostnames: createHostnames(hosts),
+			Rules:     createHTTPRouteRules(instance),
+		},
+	}
+}
+
+// createGRPCRoute renders a single rule per GRPC port. As gRPC requests are
+// not routed on their path, several GRPC ports should be attached to
+// different listeners.
+func createGRPCRoute(instance *apiv1alpha1.PodInstanciator, parent *apiv1alpha1.GatewayParentRef, hosts []string) *gatewayv1alpha2.GRPCRoute {
+	ports := getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolGRPC)
+	rules := make([]gatewayv1alpha2.GRPCRouteRule, len(ports))
+	for i, port := range ports {
+		rules[i] = gatewayv1alpha2.GRPCRouteRule{
+			BackendRefs: []gatewayv1alpha2.GRPCBackendRef{
+				{BackendRef: createBackendRef(instance, port)},
+			},
+		}
+	}
+	return &gatewayv1alpha2.GRPCRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1alpha2.GroupVersion.String(),
+			Kind:       "GRPCRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getGRPCRouteName(instance),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: gatewayv1alpha2.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{
+				ParentRefs: []gatewayv1alpha2.ParentReference{
+					createParentReference(instance, parent, parent.SectionName),
+				},
+			},
+			Hostnames: createHostnames(hosts),
+			Rules:     rules,
+		},
+	}
+}
+
+// createTCPRoute attaches a TCP port to the listener of the Gateway named
+// after the port.
+func createTCPRoute(instance *apiv1alpha1.PodInstanciator, parent *apiv1alpha1.GatewayParentRef, port apiv1alpha1.Port) *gatewayv1alpha2.TCPRoute {
+	return &gatewayv1alpha2.TCPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1alpha2.GroupVersion.String(),
+			Kind:       "TCPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getTCPRouteName(instance, port),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: gatewayv1alpha2.TCPRouteSpec{
+			CommonRouteSpec: gatewayv1alpha2.CommonRouteSpec{
+				ParentRefs: []gatewayv1alpha2.ParentReference{
+					createParentReference(instance, parent, port.PortName),
+				},
+			},
+			Rules: []gatewayv1alpha2.TCPRouteRule{
+				{BackendRefs: []gatewayv1alpha2.BackendRef{createBackendRef(instance, port)}},
+			},
+		},
+	}
+}
+
+func isRouteAccepted(parents []gatewayv1beta1.RouteParentStatus) bool {
+	if len(parents) == 0 {
+		return false
+	}
+	for _, parent := range parents {
+		if !meta.IsStatusConditionTrue(parent.Conditions, string(gatewayv1beta1.RouteConditionAccepted)) {
+			return false
+		}
+	}
+	return true
+}
+
+func getGatewayURLs(instance *apiv1alpha1.PodInstanciator, route *gatewayv1beta1.HTTPRoute) []apiv1alpha1.PortURL {
+	if len(route.Spec.Hostnames) == 0 {
+		return nil
+	}
+	host := string(route.Spec.Hostnames[0])
+	ports := getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolHTTP)
+	urls := make([]apiv1alpha1.PortURL, len(ports))
+	for i, port := range ports {
+		urls[i] = apiv1alpha1.PortURL{
+			PortName: port.PortName,
+			URL:      "http://" + host + getIngressPathName(port),
+		}
+	}
+	return urls
+}
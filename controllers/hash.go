@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+const specHashAnnotation = "api.my.domain/spec-hash"
+
+func computeHash(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+func setSpecHash(annotations map[string]string, spec interface{}) map[string]string {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[specHashAnnotation] = computeHash(spec)
+	return annotations
+}
+
+func hasSameSpecHash(desired map[string]string, found map[string]string) bool {
+	return desired[specHashAnnotation] != "" && desired[specHashAnnotation] == found[specHashAnnotation]
+}
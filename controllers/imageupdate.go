@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+const (
+	defaultImagePollInterval = 5 * time.Minute
+	imageHistoryLimit        = 10
+)
+
+func getImageUpdateStrategy(instance *apiv1alpha1.PodInstanciator) apiv1alpha1.ImageUpdateStrategy {
+	if instance.Spec.ImageUpdatePolicy.Strategy == "" {
+		return apiv1alpha1.ImageUpdateNone
+	}
+	return instance.Spec.ImageUpdatePolicy.Strategy
+}
+
+func getImagePollInterval(instance *apiv1alpha1.PodInstanciator) time.Duration {
+	if interval := instance.Spec.ImageUpdatePolicy.Interval; interval != nil && interval.Duration > 0 {
+		return interval.Duration
+	}
+	return defaultImagePollInterval
+}
+
+// isImageDigestPinned reports whether the main container runs its image by
+// digest, which following the pushes to a tag requires.
+func isImageDigestPinned(instance *apiv1alpha1.PodInstanciator) bool {
+	return instance.Spec.PinImageDigest || getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdateLatestDigest
+}
+
+// getImagePollDelay returns how long to wait before the next poll of the
+// registry, or 0 when the image is not updated.
+func getImagePollDelay(instance *apiv1alpha1.PodInstanciator) time.Duration {
+	if getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdateNone || strings.Contains(instance.Spec.ImageName, "@") {
+		return 0
+	}
+	if instance.Status.LastImagePollTime == nil {
+		return time.Second
+	}
+	delay := getImagePollInterval(instance) - time.Since(instance.Status.LastImagePollTime.Time)
+	if delay < time.Second {
+		return time.Second
+	}
+	return delay
+}
+
+func isImagePollDue(instance *apiv1alpha1.PodInstanciator) bool {
+	if getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdateNone {
+		return false
+	}
+	last := instance.Status.LastImagePollTime
+	return last == nil || time.Since(last.Time) >= getImagePollInterval(instance)
+}
+
+// splitTag splits an image reference without digest into its repository and
+// its tag, which defaults to latest.
+func splitTag(image string) (string, string) {
+	colon := strings.LastIndex(image, ":")
+	if colon <= strings.LastIndex(image, "/") {
+		return image, "latest"
+	}
+	return image[:colon], image[colon+1:]
+}
+
+// getUpdateConstraint returns the versions the strategy may move the current
+// tag to.
+func getUpdateConstraint(instance *apiv1alpha1.PodInstanciator, tag string) (*semver.Constraints, error) {
+	if getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdateSemverRange {
+		return semver.NewConstraint(instance.Spec.ImageUpdatePolicy.Range)
+	}
+	current, err := semver.NewVersion(tag)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s is not a semantic version: %w", tag, err)
+	}
+	upperBound := fmt.Sprintf("%d.0.0", current.Major()+1)
+	if getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdatePatch {
+		upperBound = fmt.Sprintf("%d.%d.0", current.Major(), current.Minor()+1)
+	}
+	return semver.NewConstraint(fmt.Sprintf(">= %s, < %s", current.String(), upperBound))
+}
+
+// validateImageUpdatePolicy checks that the update strategy can be applied to
+// the image of the instance.
+func validateImageUpdatePolicy(instance *apiv1alpha1.PodInstanciator) error {
+	strategy := getImageUpdateStrategy(instance)
+	switch strategy {
+	case apiv1alpha1.ImageUpdateSemverRange:
+		if instance.Spec.ImageUpdatePolicy.Range == "" {
+			return fmt.Errorf("the %s image update strategy requires a range", strategy)
+		}
+		if _, err := semver.NewConstraint(instance.Spec.ImageUpdatePolicy.Range); err != nil {
+			return fmt.Errorf("invalid image update range %s: %w", instance.Spec.ImageUpdatePolicy.Range, err)
+		}
+	case apiv1alpha1.ImageUpdatePatch, apiv1alpha1.ImageUpdateMinor:
+		if strings.Contains(instance.Spec.ImageName, "@") {
+			return nil
+		}
+		if _, tag := splitTag(instance.Spec.ImageName); !isSemverTag(tag) {
+			return fmt.Errorf("the %s image update strategy requires a semantic version tag, %s is not one", strategy, tag)
+		}
+	}
+	return nil
+}
+
+func isSemverTag(tag string) bool {
+	_, err := semver.NewVersion(tag)
+	return err == nil
+}
+
+// findNewestTag returns the highest of the tags satisfying the constraint,
+// or the current tag when there is none. Pre-releases are only considered
+// when the constraint asks for them. When the current tag is a version, only
+// the tags with the same v prefix are considered, so that the tag scheme of
+// the image is kept.
+func findNewestTag(tags []string, constraint *semver.Constraints, current string) string {
+	newest := current
+	var newestVersion *semver.Version
+	prefixed := strings.HasPrefix(current, "v")
+	for _, tag := range tags {
+		if isSemverTag(current) && strings.HasPrefix(tag, "v") != prefixed {
+			continue
+		}
+		version, err := semver.NewVersion(tag)
+		if err != nil || !constraint.Check(version) {
+			continue
+		}
+		if newestVersion == nil || version.GreaterThan(newestVersion) {
+			newest, newestVersion = tag, version
+		}
+	}
+	return newest
+}
+
+func findNewerImage(ctx context.Context, instance *apiv1alpha1.PodInstanciator, image string, keychain authn.Keychain) (string, error) {
+	repository, tag := splitTag(image)
+	constraint, err := getUpdateConstraint(instance, tag)
+	if err != nil {
+		return "", err
+	}
+	repo, err := name.NewRepository(repository)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+	tags, err := remote.List(repo, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return "", err
+	}
+	return repository + ":" + findNewestTag(tags, constraint, tag), nil
+}
+
+// pollImage queries the registry for the image the update strategy moves the
+// current one to. Failures are reported as Events and keep the current image,
+// so that an unreachable registry does not hold the reconciliation back.
+func pollImage(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator, image string, digest string, keychain authn.Keychain) (string, string) {
+	now := metav1.Now()
+	instance.Status.LastImagePollTime = &now
+
+	newImage, newDigest := image, digest
+	var err error
+	if getImageUpdateStrategy(instance) == apiv1alpha1.ImageUpdateLatestDigest {
+		newDigest, err = resolveDigest(ctx, image, keychain)
+	} else {
+		newImage, err = findNewerImage(ctx, instance, image, keychain)
+		if newImage != image {
+			newDigest = ""
+		}
+	}
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ImagePollFailed", "unable to poll the registry for %s: %v", image, err)
+		return image, digest
+	}
+	return newImage, newDigest
+}
+
+// recordImageUpdate appends the update to the image history of the instance,
+// which keeps the last imageHistoryLimit ones.
+func recordImageUpdate(instance *apiv1alpha1.PodInstanciator, from string, to string) *apiv1alpha1.ImageUpdate {
+	update := apiv1alpha1.ImageUpdate{From: from, To: to, Time: metav1.Now()}
+	history := append(instance.Status.ImageHistory, update)
+	if len(history) > imageHistoryLimit {
+		history = history[len(history)-imageHistoryLimit:]
+	}
+	instance.Status.ImageHistory = history
+	return &update
+}
+
+// reportImageUpdate emits the Event of an image update, once it is recorded
+// in the status.
+func reportImageUpdate(r *PodInstanciatorReconciler, instance *apiv1alpha1.PodInstanciator, update *apiv1alpha1.ImageUpdate) {
+	if update != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "ImageUpdated", "updated the image from %s to %s", update.From, update.To)
+	}
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func newUpdatedInstance(image string, strategy apiv1alpha1.ImageUpdateStrategy, versionRange string) *apiv1alpha1.PodInstanciator {
+	return &apiv1alpha1.PodInstanciator{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: apiv1alpha1.PodInstanciatorSpec{
+			ImageName:         image,
+			ImageUpdatePolicy: apiv1alpha1.ImageUpdatePolicy{Strategy: strategy, Range: versionRange},
+		},
+	}
+}
+
+var _ = Describe("splitTag", func() {
+	DescribeTable("splits the repository from the tag",
+		func(image string, repository string, tag string) {
+			actualRepository, actualTag := splitTag(image)
+			Expect(actualRepository).To(Equal(repository))
+			Expect(actualTag).To(Equal(tag))
+		},
+		Entry("untagged", "nginx", "nginx", "latest"),
+		Entry("tagged", "nginx:1.25.3", "nginx", "1.25.3"),
+		Entry("registry with a port", "localhost:5000/app", "localhost:5000/app", "latest"),
+		Entry("registry with a port and a tag", "localhost:5000/app:v1.2.3", "localhost:5000/app", "v1.2.3"),
+	)
+})
+
+var _ = Describe("getUpdateConstraint", func() {
+	DescribeTable("allows the versions of the strategy",
+		func(strategy apiv1alpha1.ImageUpdateStrategy, versionRange string, tag string, version string, allowed bool) {
+			constraint, err := getUpdateConstraint(newUpdatedInstance("app:"+tag, strategy, versionRange), tag)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(constraint.Check(semver.MustParse(version))).To(Equal(allowed))
+		},
+		Entry("patch allows a newer patch", apiv1alpha1.ImageUpdatePatch, "", "1.2.3", "1.2.9", true),
+		Entry("patch refuses a newer minor", apiv1alpha1.ImageUpdatePatch, "", "1.2.3", "1.3.0", false),
+		Entry("patch refuses an older patch", apiv1alpha1.ImageUpdatePatch, "", "1.2.3", "1.2.2", false),
+		Entry("minor allows a newer minor", apiv1alpha1.ImageUpdateMinor, "", "1.2.3", "1.9.0", true),
+		Entry("minor refuses a newer major", apiv1alpha1.ImageUpdateMinor, "", "1.2.3", "2.0.0", false),
+		Entry("range allows a version in range", apiv1alpha1.ImageUpdateSemverRange, ">= 1.0, < 3.0", "1.2.3", "2.5.0", true),
+		Entry("range refuses a version out of range", apiv1alpha1.ImageUpdateSemverRange, ">= 1.0, < 3.0", "1.2.3", "3.0.0", false),
+	)
+
+	It("refuses tags which are not versions", func() {
+		_, err := getUpdateConstraint(newUpdatedInstance("nginx", apiv1alpha1.ImageUpdatePatch, ""), "latest")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("findNewestTag", func() {
+	DescribeTable("finds the highest allowed tag",
+		func(strategy apiv1alpha1.ImageUpdateStrategy, current string, tags []string, expected string) {
+			constraint, err := getUpdateConstraint(newUpdatedInstance("app:"+current, strategy, ""), current)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(findNewestTag(tags, constraint, current)).To(Equal(expected))
+		},
+		Entry("newest patch", apiv1alpha1.ImageUpdatePatch, "1.2.3", []string{"1.2.3", "1.2.5", "1.2.4", "1.3.0"}, "1.2.5"),
+		Entry("newest minor", apiv1alpha1.ImageUpdateMinor, "1.2.3", []string{"1.2.3", "1.4.0", "1.3.9", "2.0.0"}, "1.4.0"),
+		Entry("no newer tag", apiv1alpha1.ImageUpdatePatch, "1.2.3", []string{"1.2.3", "1.3.0", "latest"}, "1.2.3"),
+		Entry("pre-releases", apiv1alpha1.ImageUpdatePatch, "1.2.3", []string{"1.2.4-rc.1"}, "1.2.3"),
+		Entry("keeps unprefixed tags", apiv1alpha1.ImageUpdatePatch, "1.2.3", []string{"1.2.4", "v1.2.5"}, "1.2.4"),
+		Entry("keeps prefixed tags", apiv1alpha1.ImageUpdatePatch, "v1.2.3", []string{"1.2.5", "v1.2.4"}, "v1.2.4"),
+	)
+
+	It("considers all the tags when the current one is not a version", func() {
+		constraint, err := semver.NewConstraint(">= 1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(findNewestTag([]string{"1.2.0", "v1.3.0", "latest"}, constraint, "latest")).To(Equal("v1.3.0"))
+	})
+})
+
+var _ = Describe("validateImageUpdatePolicy", func() {
+	DescribeTable("checks the strategy applies to the image",
+		func(image string, strategy apiv1alpha1.ImageUpdateStrategy, versionRange string, valid bool) {
+			err := validateImageUpdatePolicy(newUpdatedInstance(image, strategy, versionRange))
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("no strategy", "nginx", apiv1alpha1.ImageUpdateStrategy(""), "", true),
+		Entry("latest digest of an untagged image", "nginx", apiv1alpha1.ImageUpdateLatestDigest, "", true),
+		Entry("patch of a version", "nginx:1.25.3", apiv1alpha1.ImageUpdatePatch, "", true),
+		Entry("minor of a prefixed version", "app:v1.2.3", apiv1alpha1.ImageUpdateMinor, "", true),
+		Entry("patch of an untagged image", "nginx", apiv1alpha1.ImageUpdatePatch, "", false),
+		Entry("minor of latest", "nginx:latest", apiv1alpha1.ImageUpdateMinor, "", false),
+		Entry("patch of an image referenced by digest", "nginx@sha256:0123", apiv1alpha1.ImageUpdatePatch, "", true),
+		Entry("range", "nginx", apiv1alpha1.ImageUpdateSemverRange, ">= 1.25", true),
+		Entry("empty range", "nginx:1.25.3", apiv1alpha1.ImageUpdateSemverRange, "", false),
+		Entry("invalid range", "nginx:1.25.3", apiv1alpha1.ImageUpdateSemverRange, ">= one", false),
+	)
+})
+
+var _ = Describe("recordImageUpdate", func() {
+	It("keeps the last updates", func() {
+		instance := newUpdatedInstance("app:1.0.0", apiv1alpha1.ImageUpdatePatch, "")
+		for i := 0; i < imageHistoryLimit; i++ {
+			recordImageUpdate(instance, fmt.Sprintf("app:1.0.%d", i), fmt.Sprintf("app:1.0.%d", i+1))
+		}
+		Expect(instance.Status.ImageHistory).To(HaveLen(imageHistoryLimit))
+
+		update := recordImageUpdate(instance, "app:1.0.10", "app:1.0.11")
+		Expect(update.From).To(Equal("app:1.0.10"))
+		Expect(update.To).To(Equal("app:1.0.11"))
+		Expect(instance.Status.ImageHistory).To(HaveLen(imageHistoryLimit))
+		Expect(instance.Status.ImageHistory[0].From).To(Equal("app:1.0.1"))
+		Expect(instance.Status.ImageHistory[imageHistoryLimit-1]).To(Equal(*update))
+	})
+})
+
+var _ = Describe("getImage updates", func() {
+	var (
+		ctx      context.Context
+		r        *PodInstanciatorReconciler
+		recorder *record.FakeRecorder
+		host     string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		r, recorder = newImageReconciler()
+		host = startTestRegistry()
+	})
+
+	// pollAgain makes the next poll due.
+	pollAgain := func(instance *apiv1alpha1.PodInstanciator) {
+		last := metav1.NewTime(time.Now().Add(-defaultImagePollInterval))
+		instance.Status.LastImagePollTime = &last
+	}
+
+	It("moves to the newest patch with the same tag prefix", func() {
+		for _, tag := range []string{"1.2.3", "1.2.4", "v1.2.5", "1.3.0"} {
+			pushTestImage(host + "/app:" + tag)
+		}
+		instance := newUpdatedInstance(host+"/app:1.2.3", apiv1alpha1.ImageUpdatePatch, "")
+
+		image, first, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.4"))
+		Expect(first).NotTo(BeNil())
+		Expect(first.From).To(Equal(host + "/app:1.2.3"))
+		Expect(first.To).To(Equal(host + "/app:1.2.4"))
+		Expect(instance.Status.LastImagePollTime).NotTo(BeNil())
+
+		pushTestImage(host + "/app:1.2.6")
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.4"))
+		Expect(update).To(BeNil())
+
+		pollAgain(instance)
+		image, update, err = getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.6"))
+		Expect(update).NotTo(BeNil())
+		Expect(update.From).To(Equal(host + "/app:1.2.4"))
+		Expect(instance.Status.ImageHistory).To(Equal([]apiv1alpha1.ImageUpdate{*first, *update}))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("pins the tag it moves to", func() {
+		pushTestImage(host + "/app:1.2.3")
+		digest := pushTestImage(host + "/app:1.2.4")
+		instance := newUpdatedInstance(host+"/app:1.2.3", apiv1alpha1.ImageUpdatePatch, "")
+		instance.Spec.PinImageDigest = true
+
+		image, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.4@" + digest))
+	})
+
+	It("follows the pushes to the tag", func() {
+		first := pushTestImage(host + "/app:latest")
+		instance := newUpdatedInstance(host+"/app", apiv1alpha1.ImageUpdateLatestDigest, "")
+
+		image, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app@" + first))
+
+		second := pushTestImage(host + "/app:latest")
+		pollAgain(instance)
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app@" + second))
+		Expect(update.From).To(Equal(host + "/app@" + first))
+		Expect(instance.Status.ImageDigest).To(Equal(second))
+	})
+
+	It("keeps the image when the registry cannot be polled", func() {
+		instance := newUpdatedInstance(host+"/missing:1.2.3", apiv1alpha1.ImageUpdatePatch, "")
+
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/missing:1.2.3"))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.LastImagePollTime).NotTo(BeNil())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning ImagePollFailed")))
+	})
+})
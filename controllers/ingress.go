@@ -1,15 +1,48 @@
 package controllers
 
 import (
+	"strings"
+	"text/template"
+
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
 )
 
+const (
+	rewriteTargetAnnotation = "nginx.ingress.kubernetes.io/rewrite-target"
+	issuerAnnotation        = "cert-manager.io/issuer"
+	clusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+)
+
+// getDefaultHosts renders the operator default host template for the
+// instance.
+func getDefaultHosts(instance *apiv1alpha1.PodInstanciator, hostTemplate *template.Template) ([]string, error) {
+	if hostTemplate == nil {
+		return []string{""}, nil
+	}
+	var host strings.Builder
+	if err := hostTemplate.Execute(&host, instance); err != nil {
+		return nil, err
+	}
+	return []string{host.String()}, nil
+}
+
+func getIngressHosts(instance *apiv1alpha1.PodInstanciator, hostTemplate *template.Template) ([]string, error) {
+	if len(instance.Spec.Ingress.Hosts) > 0 {
+		return instance.Spec.Ingress.Hosts, nil
+	}
+	return getDefaultHosts(instance, hostTemplate)
+}
+
 func createIngressPaths(instance *apiv1alpha1.PodInstanciator) []networkingv1.HTTPIngressPath {
-	paths := make([]networkingv1.HTTPIngressPath, len(instance.Spec.Ports))
-	pathType := networkingv1.PathTypePrefix
-	for i, port := range instance.Spec.Ports {
+	ports := getIngressPorts(instance)
+	paths := make([]networkingv1.HTTPIngressPath, len(ports))
+	pathType := instance.Spec.Ingress.PathType
+	if pathType == "" {
+		pathType = networkingv1.PathTypePrefix
+	}
+	for i, port := range ports {
 		paths[i] = networkingv1.HTTPIngressPath{
 			Path:     getIngressPathName(port),
 			PathType: &pathType,
@@ -26,26 +59,81 @@ func createIngressPaths(instance *apiv1alpha1.PodInstanciator) []networkingv1.HT
 	return paths
 }
 
-func createIngress(instance *apiv1alpha1.PodInstanciator) *networkingv1.Ingress {
+func createIngressAnnotations(instance *apiv1alpha1.PodInstanciator) map[string]string {
+	annotations := map[string]string{}
+	if rewrite := instance.Spec.Ingress.Rewrite; rewrite == nil || *rewrite {
+		annotations[rewriteTargetAnnotation] = "/"
+	}
+	if tls := instance.Spec.Ingress.TLS; tls != nil {
+		if tls.Issuer != "" {
+			annotations[issuerAnnotation] = tls.Issuer
+		}
+		if tls.ClusterIssuer != "" {
+			annotations[clusterIssuerAnnotation] = tls.ClusterIssuer
+		}
+	}
+	for key, value := range instance.Spec.Ingress.Annotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
+func createIngress(instance *apiv1alpha1.PodInstanciator, hosts []string) *networkingv1.Ingress {
+	rules := make([]networkingv1.IngressRule, len(hosts))
+	for i, host := range hosts {
+		rules[i] = networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: createIngressPaths(instance),
+				},
+			},
+		}
+	}
+	var tls []networkingv1.IngressTLS
+	if instance.Spec.Ingress.TLS != nil {
+		tls = []networkingv1.IngressTLS{
+			{
+				Hosts:      hosts,
+				SecretName: getTLSSecretName(instance),
+			},
+		}
+	}
 	return &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getIngressName(instance),
-			Namespace: instance.Namespace,
-			Annotations: map[string]string{
-				"nginx.ingress.kubernetes.io/rewrite-target": "/",
-			},
+			Name:        getIngressName(instance),
+			Namespace:   instance.Namespace,
+			Labels:      getLabels(instance),
+			Annotations: createIngressAnnotations(instance),
 		},
 		Spec: networkingv1.IngressSpec{
-			Rules: []networkingv1.IngressRule{
-				{
-					Host: "worker.127.0.0.1.sslip.io",
-					IngressRuleValue: networkingv1.IngressRuleValue{
-						HTTP: &networkingv1.HTTPIngressRuleValue{
-							Paths: createIngressPaths(instance),
-						},
-					},
-				},
-			},
+			IngressClassName: instance.Spec.Ingress.IngressClassName,
+			Rules:            rules,
+			TLS:              tls,
 		},
 	}
 }
+
+func getIngressURLs(instance *apiv1alpha1.PodInstanciator, ingress *networkingv1.Ingress) []apiv1alpha1.PortURL {
+	if len(ingress.Spec.Rules) == 0 || ingress.Spec.Rules[0].Host == "" {
+		return nil
+	}
+	host := ingress.Spec.Rules[0].Host
+	scheme := "http://"
+	if len(ingress.Spec.TLS) > 0 {
+		scheme = "https://"
+	}
+	ports := getIngressPorts(instance)
+	urls := make([]apiv1alpha1.PortURL, len(ports))
+	for i, port := range ports {
+		urls[i] = apiv1alpha1.PortURL{
+			PortName: port.PortName,
+			URL:      scheme + host + getIngressPathName(port),
+		}
+	}
+	return urls
+}
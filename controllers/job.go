@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// createJobSpec renders the Job run by both the Job and CronJob workload
+// kinds. Failed Pods are kept so that their logs can be read.
+func createJobSpec(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) batchv1.JobSpec {
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	return batchv1.JobSpec{
+		Completions:           instance.Spec.Workload.Completions,
+		Parallelism:           instance.Spec.Workload.Parallelism,
+		BackoffLimit:          instance.Spec.Workload.BackoffLimit,
+		ActiveDeadlineSeconds: instance.Spec.Workload.ActiveDeadlineSeconds,
+		Template:              template,
+	}
+}
+
+func createJob(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) *batchv1.Job {
+	spec := createJobSpec(instance, template)
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getJobName(instance),
+			Namespace:   instance.Namespace,
+			Labels:      getLabels(instance),
+			Annotations: setSpecHash(nil, spec),
+		},
+		Spec: spec,
+	}
+}
+
+// jobNeedsRecreate reports whether the rendered spec changed since the Job was
+// created, since its Pod template cannot be updated.
+func jobNeedsRecreate(resource client.Object, foundResource client.Object) bool {
+	return !hasSameSpecHash(resource.GetAnnotations(), foundResource.GetAnnotations())
+}
+
+func createCronJob(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "CronJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getCronJobName(instance),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          instance.Spec.Workload.Schedule,
+			ConcurrencyPolicy: instance.Spec.Workload.ConcurrencyPolicy,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: getLabels(instance),
+				},
+				Spec: createJobSpec(instance, template),
+			},
+		},
+	}
+}
+
+func getJobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isJobComplete(job *batchv1.Job) bool {
+	return getJobCondition(job, batchv1.JobComplete)
+}
+
+func isJobFailed(job *batchv1.Job) bool {
+	return getJobCondition(job, batchv1.JobFailed)
+}
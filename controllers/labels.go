@@ -0,0 +1,17 @@
+package controllers
+
+import apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+
+const managedBy = "podinstanciator"
+
+func getSelectorLabels(instance *apiv1alpha1.PodInstanciator) map[string]string {
+	return map[string]string{"app": getPodName(instance)}
+}
+
+func getLabels(instance *apiv1alpha1.PodInstanciator) map[string]string {
+	labels := getSelectorLabels(instance)
+	labels["app.kubernetes.io/name"] = instance.Name
+	labels["app.kubernetes.io/instance"] = instance.Name
+	labels["app.kubernetes.io/managed-by"] = managedBy
+	return labels
+}
@@ -4,33 +4,103 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func createPodPorts(instance *apiv1alpha1.PodInstanciator) []corev1.ContainerPort {
-	ports := make([]corev1.ContainerPort, len(instance.Spec.Ports))
-	for i, port := range instance.Spec.Ports {
+func createContainerPorts(specPorts []apiv1alpha1.Port) []corev1.ContainerPort {
+	ports := make([]corev1.ContainerPort, len(specPorts))
+	for i, port := range specPorts {
 		ports[i] = corev1.ContainerPort{
 			Name:          port.PortName,
 			ContainerPort: port.PortNumber,
+			Protocol:      getProtocol(port),
 		}
 	}
 	return ports
 }
 
-func createPod(instance *apiv1alpha1.PodInstanciator) *corev1.Pod {
-	return &corev1.Pod{
+func createContainers(r *PodInstanciatorReconciler, instance *apiv1alpha1.PodInstanciator, specContainers []apiv1alpha1.Container) []corev1.Container {
+	containers := make([]corev1.Container, len(specContainers))
+	for i, container := range specContainers {
+		containers[i] = corev1.Container{
+			Name:            container.Name,
+			Image:           container.Image,
+			ImagePullPolicy: instance.Spec.ImagePullPolicy,
+			Command:         container.Command,
+			Args:            container.Args,
+			Ports:           createContainerPorts(container.Ports),
+			Env:             container.Env,
+			EnvFrom:         container.EnvFrom,
+			VolumeMounts:    container.VolumeMounts,
+			Resources:       getResources(container.Resources, r.DefaultResources),
+		}
+	}
+	return containers
+}
+
+// createPodTemplate renders the Pod run by every workload kind. The main
+// container comes first, followed by the sidecars.
+func createPodTemplate(r *PodInstanciatorReconciler, instance *apiv1alpha1.PodInstanciator) corev1.PodTemplateSpec {
+	template := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getPodName(instance),
-			Namespace: instance.Namespace,
+			Labels: getLabels(instance),
 		},
 		Spec: corev1.PodSpec{
 			Containers: []corev1.Container{
 				{
-					Name:  getPodName(instance),
-					Image: instance.Spec.ImageName,
-					Ports: createPodPorts(instance),
+					Name:            getPodName(instance),
+					Image:           instance.Spec.ImageName,
+					ImagePullPolicy: instance.Spec.ImagePullPolicy,
+					Command:         instance.Spec.Command,
+					Args:            instance.Spec.Args,
+					WorkingDir:      instance.Spec.WorkingDir,
+					Ports:           createContainerPorts(instance.Spec.Ports),
+					Env:             instance.Spec.Env,
+					EnvFrom:         instance.Spec.EnvFrom,
+					VolumeMounts:    createVolumeMounts(instance),
+					Resources:       getResources(instance.Spec.Resources, r.DefaultResources),
+					ReadinessProbe:  getReadinessProbe(instance),
+					LivenessProbe:   getLivenessProbe(instance),
+					StartupProbe:    instance.Spec.Probes.Startup,
+					Lifecycle:       instance.Spec.Lifecycle,
 				},
 			},
+			ImagePullSecrets:              getImagePullSecrets(r, instance),
+			InitContainers:                createContainers(r, instance, instance.Spec.InitContainers),
+			Volumes:                       createVolumes(instance),
+			TerminationGracePeriodSeconds: instance.Spec.TerminationGracePeriodSeconds,
+			NodeSelector:                  instance.Spec.Scheduling.NodeSelector,
+			Affinity:                      instance.Spec.Scheduling.Affinity,
+			Tolerations:                   instance.Spec.Scheduling.Tolerations,
+			TopologySpreadConstraints:     createTopologySpreadConstraints(instance),
+			PriorityClassName:             instance.Spec.Scheduling.PriorityClassName,
+			RuntimeClassName:              instance.Spec.Scheduling.RuntimeClassName,
+		},
+	}
+	template.Spec.Containers = append(template.Spec.Containers, createContainers(r, instance, instance.Spec.Sidecars)...)
+	return template
+}
+
+func createPod(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Pod",
 		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getPodName(instance),
+			Namespace:   instance.Namespace,
+			Labels:      template.Labels,
+			Annotations: setSpecHash(template.Annotations, template.Spec),
+		},
+		Spec: template.Spec,
 	}
 }
+
+// podNeedsRecreate reports whether the rendered spec or the referenced
+// configuration changed since the Pod was created, since most of the fields of
+// a running Pod are immutable.
+func podNeedsRecreate(resource client.Object, foundResource client.Object) bool {
+	return !hasSameSpecHash(resource.GetAnnotations(), foundResource.GetAnnotations()) ||
+		resource.GetAnnotations()[configHashAnnotation] != foundResource.GetAnnotations()[configHashAnnotation]
+}
@@ -18,28 +18,78 @@ package controllers
 
 import (
 	"context"
+	"text/template"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
 )
 
+// recreateRequeueDelay leaves time for a deleted resource to be gone before it
+// is created again.
+const recreateRequeueDelay = 2 * time.Second
+
+// conflictRequeueDelay is how often the fields rendered by the operator are
+// taken back while another field manager keeps changing them.
+const conflictRequeueDelay = time.Minute
+
 // PodInstanciatorReconciler reconciles a PodInstanciator object
 type PodInstanciatorReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// IngressHostTemplate renders the Ingress host of the instances which do
+	// not set any, with the PodInstanciator as data.
+	IngressHostTemplate *template.Template
+	// DefaultExposure is the exposure mode of the instances which do not set
+	// any.
+	DefaultExposure apiv1alpha1.ExposureMode
+	// DefaultGateway is the Gateway the routes are attached to when the
+	// instances do not set any.
+	DefaultGateway *apiv1alpha1.GatewayParentRef
+	// DefaultResources fills the requests and limits the instances do not
+	// set.
+	DefaultResources corev1.ResourceRequirements
+	// DefaultPullSecret is added to the pull secrets of every instance, and
+	// copied from its namespace into the namespaces of the instances.
+	DefaultPullSecret *types.NamespacedName
+	// Recorder publishes the image updates as Events.
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=api.my.domain,resources=podinstanciators,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=api.my.domain,resources=podinstanciators/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=api.my.domain,resources=podinstanciators/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;grpcroutes;tcproutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -50,19 +100,6 @@ type PodInstanciatorReconciler struct {
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.14.1/pkg/reconcile
-
-func applyResource(r *PodInstanciatorReconciler, ctx context.Context, resource client.Object, foundResource client.Object) error {
-	err := r.Get(ctx, types.NamespacedName{Name: resource.GetName(), Namespace: resource.GetNamespace()}, foundResource)
-	if err != nil && errors.IsNotFound(err) {
-		err = r.Create(ctx, resource)
-		if err != nil {
-			return err
-		}
-		return nil
-	}
-	return err
-}
-
 func (r *PodInstanciatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.Log.WithValues("PodInstanciator", req.NamespacedName)
 
@@ -76,46 +113,154 @@ func (r *PodInstanciatorReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, err
 	}
 
-	pod := createPod(instance)
-	svc := createService(instance)
-	ingress := createIngress(instance)
-
-	if err := controllerutil.SetControllerReference(instance, pod, r.Scheme); err != nil {
-		return ctrl.Result{}, err
+	template := createPodTemplate(r, instance)
+	if err := validateSpec(instance, template); err != nil {
+		logger.Info("invalid spec", "error", err.Error())
+		return ctrl.Result{}, updateInvalidStatus(r, ctx, instance, err)
 	}
 
-	if err := controllerutil.SetControllerReference(instance, svc, r.Scheme); err != nil {
+	image, imageUpdate, err := getImage(r, ctx, instance)
+	if err != nil {
+		logger.Error(err, "unable to resolve the image digest", "image", instance.Spec.ImageName)
 		return ctrl.Result{}, err
 	}
+	template.Spec.Containers[0].Image = image
 
-	if err := controllerutil.SetControllerReference(instance, ingress, r.Scheme); err != nil {
+	configHash, err := computeConfigHash(r, ctx, instance)
+	if err != nil {
+		logger.Error(err, "unable to hash the referenced configuration")
 		return ctrl.Result{}, err
 	}
+	template.Annotations = setConfigHash(template.Annotations, configHash)
 
-	err = applyResource(r, ctx, pod, &corev1.Pod{})
+	var resources []generatedResource
+	if len(instance.Spec.Files) > 0 {
+		resources = append(resources, generatedResource{resource: createConfigMap(instance), foundResource: &corev1.ConfigMap{}})
+	}
+	pullSecret, err := createPullSecretCopy(r, ctx, instance)
 	if err != nil {
-		logger.Error(err, "unable to create Pod")
+		logger.Error(err, "unable to render the default pull secret")
 		return ctrl.Result{}, err
 	}
-	err = applyResource(r, ctx, svc, &corev1.Service{})
+	if pullSecret != nil {
+		resources = append(resources, generatedResource{resource: pullSecret, foundResource: &corev1.Secret{}, retain: true})
+	}
+	resources = append(resources, createVolumeClaimResources(instance)...)
+	resources = append(resources, createWorkloadResources(instance, template)...)
+	if hasService(instance) {
+		resources = append(resources, generatedResource{
+			resource: createService(instance), foundResource: &corev1.Service{}, needsRecreate: serviceNeedsRecreate,
+		})
+	}
+	exposure, err := createExposureResources(r, instance)
 	if err != nil {
-		logger.Error(err, "unable to create Service")
+		logger.Error(err, "unable to render exposure resources")
 		return ctrl.Result{}, err
 	}
-	err = applyResource(r, ctx, ingress, &networkingv1.Ingress{})
-	if err != nil {
-		logger.Error(err, "unable to create Ingress")
+	resources = append(resources, exposure...)
+
+	var conflicts []string
+	recreated := false
+	for _, generated := range resources {
+		kind := generated.resource.GetObjectKind().GroupVersionKind().Kind
+		if !generated.retain {
+			if err := controllerutil.SetControllerReference(instance, generated.resource, r.Scheme); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		resourceRecreated, err := applyResource(r, ctx, generated)
+		if errors.IsConflict(err) {
+			conflicts = append(conflicts, kind+": "+err.Error())
+			continue
+		}
+		if err != nil {
+			logger.Error(err, "unable to apply "+kind)
+			return ctrl.Result{}, err
+		}
+		recreated = recreated || resourceRecreated
+	}
+
+	for _, list := range ownedResourceLists() {
+		if err := deleteUnwantedResources(r, ctx, instance, list, resources); err != nil {
+			logger.Error(err, "unable to delete unwanted resources")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := updateStatus(r, ctx, instance, conflicts); err != nil {
+		logger.Error(err, "unable to update PodInstanciator status")
 		return ctrl.Result{}, err
 	}
+	reportImageUpdate(r, instance, imageUpdate)
+
+	if len(conflicts) > 0 {
+		logger.Info("field ownership conflicts on generated resources", "conflicts", conflicts)
+		return ctrl.Result{RequeueAfter: conflictRequeueDelay}, nil
+	}
+	if recreated {
+		logger.Info("resources are being recreated")
+		return ctrl.Result{RequeueAfter: recreateRequeueDelay}, nil
+	}
 
-	logger.Info("all resources created!")
+	logger.Info("all resources are up to date!")
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: getImagePollDelay(instance)}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Every generated resource is watched so that deleting or editing it triggers
+// a reconciliation restoring it, and so are the Secrets and ConfigMaps the
+// instances refer to.
+// The Gateway API routes are only watched when their CRDs are installed.
 func (r *PodInstanciatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1alpha1.PodInstanciator{}, secretIndexKey, indexReferencedSecrets)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1alpha1.PodInstanciator{}, configMapIndexKey, indexReferencedConfigMaps)
+	if err != nil {
+		return err
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1alpha1.PodInstanciator{}).
-		Complete(r)
+		Owns(&corev1.Pod{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&networkingv1.Ingress{}).
+		Watches(
+			&source.Kind{Type: &discoveryv1.EndpointSlice{}},
+			handler.EnqueueRequestsFromMapFunc(r.findInstanceForEndpointSlice),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.findInstancesForSecret),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.findInstancesForConfigMap),
+		)
+
+	routes := []client.Object{&gatewayv1beta1.HTTPRoute{}, &gatewayv1alpha2.GRPCRoute{}, &gatewayv1alpha2.TCPRoute{}}
+	for _, route := range routes {
+		gvk, err := apiutil.GVKForObject(route, r.Scheme)
+		if err != nil {
+			return err
+		}
+		_, err = mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		builder = builder.Owns(route)
+	}
+
+	return builder.Complete(r)
 }
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// getPorts returns the ports of the main container and of the sidecars.
+func getPorts(instance *apiv1alpha1.PodInstanciator) []apiv1alpha1.Port {
+	ports := append([]apiv1alpha1.Port{}, instance.Spec.Ports...)
+	for _, sidecar := range instance.Spec.Sidecars {
+		ports = append(ports, sidecar.Ports...)
+	}
+	return ports
+}
+
+// hasPorts reports whether the instance declares ports, without which there is
+// nothing to expose.
+func hasPorts(instance *apiv1alpha1.PodInstanciator) bool {
+	return len(getPorts(instance)) > 0
+}
+
+// getProtocol defaults the protocol of the port, which the CRD schema cannot do
+// for a field of the core Protocol type.
+func getProtocol(port apiv1alpha1.Port) corev1.Protocol {
+	if port.Protocol == "" {
+		return corev1.ProtocolTCP
+	}
+	return port.Protocol
+}
+
+func getAppProtocol(port apiv1alpha1.Port) apiv1alpha1.AppProtocol {
+	if port.AppProtocol == "" {
+		return apiv1alpha1.AppProtocolHTTP
+	}
+	return port.AppProtocol
+}
+
+// getPortsByAppProtocol returns the ports of the instance speaking one of the
+// given application protocols.
+func getPortsByAppProtocol(instance *apiv1alpha1.PodInstanciator, protocols ...apiv1alpha1.AppProtocol) []apiv1alpha1.Port {
+	var ports []apiv1alpha1.Port
+	for _, port := range getPorts(instance) {
+		for _, protocol := range protocols {
+			if getAppProtocol(port) == protocol {
+				ports = append(ports, port)
+				break
+			}
+		}
+	}
+	return ports
+}
+
+func getIngressPorts(instance *apiv1alpha1.PodInstanciator) []apiv1alpha1.Port {
+	return getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolHTTP, apiv1alpha1.AppProtocolGRPC)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// createHealthCheckProbe renders an HTTP probe against the first port with a
+// health check path, if any.
+func createHealthCheckProbe(instance *apiv1alpha1.PodInstanciator) *corev1.Probe {
+	for _, port := range instance.Spec.Ports {
+		if port.HealthCheckPath == "" {
+			continue
+		}
+		return &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: port.HealthCheckPath,
+					Port: intstr.FromString(port.PortName),
+				},
+			},
+		}
+	}
+	return nil
+}
+
+func getReadinessProbe(instance *apiv1alpha1.PodInstanciator) *corev1.Probe {
+	if instance.Spec.Probes.Readiness != nil {
+		return instance.Spec.Probes.Readiness
+	}
+	return createHealthCheckProbe(instance)
+}
+
+func getLivenessProbe(instance *apiv1alpha1.PodInstanciator) *corev1.Probe {
+	if instance.Spec.Probes.Liveness != nil {
+		return instance.Spec.Probes.Liveness
+	}
+	return createHealthCheckProbe(instance)
+}
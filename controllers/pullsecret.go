@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func isDefaultPullSecret(r *PodInstanciatorReconciler, secret client.Object) bool {
+	return r.DefaultPullSecret != nil && secret.GetName() == r.DefaultPullSecret.Name
+}
+
+// getImagePullSecrets returns the pull secrets of the instance followed by the
+// operator default one.
+func getImagePullSecrets(r *PodInstanciatorReconciler, instance *apiv1alpha1.PodInstanciator) []corev1.LocalObjectReference {
+	secrets := instance.Spec.ImagePullSecrets
+	if r.DefaultPullSecret == nil {
+		return secrets
+	}
+	for _, secret := range secrets {
+		if secret.Name == r.DefaultPullSecret.Name {
+			return secrets
+		}
+	}
+	return append(append([]corev1.LocalObjectReference{}, secrets...), corev1.LocalObjectReference{Name: r.DefaultPullSecret.Name})
+}
+
+// createPullSecretCopy renders the copy of the default pull secret in the
+// namespace of the instance. The copy is shared by the instances of the
+// namespace, so it is not owned by any of them. Nothing is rendered when the
+// instance lives next to the default pull secret, when the latter does not
+// exist, or when a Secret of the same name not managed by the operator is
+// already there.
+func createPullSecretCopy(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (*corev1.Secret, error) {
+	if r.DefaultPullSecret == nil || r.DefaultPullSecret.Namespace == instance.Namespace {
+		return nil, nil
+	}
+	source := &corev1.Secret{}
+	found, err := getResource(r, ctx, r.DefaultPullSecret.Name, r.DefaultPullSecret.Namespace, source)
+	if err != nil || !found {
+		return nil, err
+	}
+	existing := &corev1.Secret{}
+	found, err = getResource(r, ctx, r.DefaultPullSecret.Name, instance.Namespace, existing)
+	if err != nil {
+		return nil, err
+	}
+	if found && existing.Labels["app.kubernetes.io/managed-by"] != managedBy {
+		return nil, nil
+	}
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: instance.Namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": managedBy},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}, nil
+}
@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// registryTimeout bounds the requests to the registries. The image is
+// resolved inline by the single reconcile worker, so an unresponsive registry
+// holds back every instance until it times out.
+const registryTimeout = 5 * time.Second
+
+// pullSecretKeychain authenticates to registries with the credentials of the
+// pull secrets, falling back to anonymous pulls.
+type pullSecretKeychain map[string]authn.AuthConfig
+
+// normalizeRegistry turns the keys of Docker configs, such as
+// https://index.docker.io/v1/, into registry hosts.
+func normalizeRegistry(key string) string {
+	if _, host, found := strings.Cut(key, "://"); found {
+		key = host
+	}
+	key, _, _ = strings.Cut(key, "/")
+	if key == "docker.io" || key == "registry-1.docker.io" {
+		return name.DefaultRegistry
+	}
+	return key
+}
+
+func (k pullSecretKeychain) Resolve(resource authn.Resource) (authn.Authenticator, error) {
+	if config, found := k[normalizeRegistry(resource.RegistryStr())]; found {
+		return authn.FromConfig(config), nil
+	}
+	return authn.Anonymous, nil
+}
+
+// addPullSecret reads the credentials of a dockerconfigjson or dockercfg
+// Secret. Malformed Secrets are skipped, as the kubelet would do.
+func (k pullSecretKeychain) addPullSecret(secret *corev1.Secret) {
+	var auths map[string]authn.AuthConfig
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		config := struct {
+			Auths map[string]authn.AuthConfig `json:"auths"`
+		}{}
+		if json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config) != nil {
+			return
+		}
+		auths = config.Auths
+	case corev1.SecretTypeDockercfg:
+		if json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths) != nil {
+			return
+		}
+	}
+	for key, config := range auths {
+		registry := normalizeRegistry(key)
+		if _, found := k[registry]; !found {
+			k[registry] = config
+		}
+	}
+}
+
+// getKeychain builds the keychain of the pull secrets of the instance, in
+// their order of precedence. The default pull secret is read from its own
+// namespace until its copy is applied, since the image is resolved first.
+func getKeychain(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (authn.Keychain, error) {
+	keychain := pullSecretKeychain{}
+	for _, ref := range getImagePullSecrets(r, instance) {
+		secret := &corev1.Secret{}
+		found, err := getResource(r, ctx, ref.Name, instance.Namespace, secret)
+		if err != nil {
+			return nil, err
+		}
+		if !found && r.DefaultPullSecret != nil && ref.Name == r.DefaultPullSecret.Name {
+			found, err = getResource(r, ctx, ref.Name, r.DefaultPullSecret.Namespace, secret)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if found {
+			keychain.addPullSecret(secret)
+		}
+	}
+	return keychain, nil
+}
+
+// resolveDigest returns the digest of the manifest image points to. Registries
+// which do not support HEAD requests are sent a GET instead.
+func resolveDigest(ctx context.Context, image string, keychain authn.Keychain) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, registryTimeout)
+	defer cancel()
+	options := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}
+	descriptor, err := remote.Head(ref, options...)
+	if err == nil {
+		return descriptor.Digest.String(), nil
+	}
+	fullDescriptor, err := remote.Get(ref, options...)
+	if err != nil {
+		return "", err
+	}
+	return fullDescriptor.Digest.String(), nil
+}
+
+// getImage returns the image of the main container. It starts over from
+// imageName when it changes, and otherwise from the image found in the
+// status, which the update policy moves to newer tags or digests. Images
+// referenced by digest are used as is. Only the moves of the update policy are
+// recorded as updates, not the digests added or dropped by pinning. The
+// update, if any, is returned so that it is reported once the status is
+// written.
+func getImage(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (string, *apiv1alpha1.ImageUpdate, error) {
+	if strings.Contains(instance.Spec.ImageName, "@") {
+		_, digest, _ := strings.Cut(instance.Spec.ImageName, "@")
+		instance.Status.Image = instance.Spec.ImageName
+		instance.Status.ImageDigest = digest
+		instance.Status.ImageSource = instance.Spec.ImageName
+		return instance.Status.Image, nil, nil
+	}
+
+	previous := instance.Status.Image
+	if instance.Status.ImageSource != instance.Spec.ImageName {
+		previous = ""
+		instance.Status.ImageSource = instance.Spec.ImageName
+		instance.Status.LastImagePollTime = nil
+	}
+	image, digest := instance.Spec.ImageName, ""
+	if previous != "" {
+		image, digest, _ = strings.Cut(previous, "@")
+	}
+
+	var keychain authn.Keychain
+	var err error
+	moved := false
+	if isImagePollDue(instance) {
+		keychain, err = getKeychain(r, ctx, instance)
+		if err != nil {
+			return "", nil, err
+		}
+		polledImage, polledDigest := pollImage(r, ctx, instance, image, digest, keychain)
+		moved = polledImage != image || (digest != "" && polledDigest != digest)
+		image, digest = polledImage, polledDigest
+	}
+
+	if !isImageDigestPinned(instance) {
+		digest = ""
+	} else if digest == "" {
+		if keychain == nil {
+			keychain, err = getKeychain(r, ctx, instance)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		digest, err = resolveDigest(ctx, image, keychain)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if digest != "" {
+		image += "@" + digest
+	}
+	var update *apiv1alpha1.ImageUpdate
+	if moved {
+		from := previous
+		if from == "" {
+			from = instance.Spec.ImageName
+		}
+		update = recordImageUpdate(instance, from, image)
+	}
+	instance.Status.Image = image
+	instance.Status.ImageDigest = digest
+	return image, update, nil
+}
@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// startTestRegistry serves an in-process registry for the duration of the
+// spec and returns its host.
+func startTestRegistry() string {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	DeferCleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// startPrivateTestRegistry serves an in-process registry which requires the
+// basic credentials for the duration of the spec and returns its host.
+func startPrivateTestRegistry(username string, password string) string {
+	handler := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if user, pass, ok := req.BasicAuth(); !ok || user != username || pass != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	}))
+	DeferCleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// pushTestImage pushes a random image to the reference and returns its digest.
+func pushTestImage(reference string, options ...remote.Option) string {
+	ref, err := name.ParseReference(reference)
+	Expect(err).NotTo(HaveOccurred())
+	image, err := random.Image(256, 1)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(remote.Write(ref, image, options...)).To(Succeed())
+	digest, err := image.Digest()
+	Expect(err).NotTo(HaveOccurred())
+	return digest.String()
+}
+
+// newImageReconciler returns a reconciler reading the objects, whose Events
+// are buffered, for the specs which do not need a cluster.
+func newImageReconciler(objects ...client.Object) (*PodInstanciatorReconciler, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(10)
+	return &PodInstanciatorReconciler{
+		Client:   fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme.Scheme,
+		Recorder: recorder,
+	}, recorder
+}
+
+func newDockerConfigSecret(auths map[string]authn.AuthConfig) *corev1.Secret {
+	data, err := json.Marshal(map[string]interface{}{"auths": auths})
+	Expect(err).NotTo(HaveOccurred())
+	return &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: data},
+	}
+}
+
+var _ = Describe("normalizeRegistry", func() {
+	DescribeTable("turns Docker config keys into registry hosts",
+		func(key string, expected string) {
+			Expect(normalizeRegistry(key)).To(Equal(expected))
+		},
+		Entry("Docker Hub URL", "https://index.docker.io/v1/", name.DefaultRegistry),
+		Entry("Docker Hub alias", "docker.io", name.DefaultRegistry),
+		Entry("Docker Hub registry host", "registry-1.docker.io", name.DefaultRegistry),
+		Entry("host", "ghcr.io", "ghcr.io"),
+		Entry("host with a path", "quay.io/organization", "quay.io"),
+		Entry("URL with a port", "http://localhost:5000/v2/", "localhost:5000"),
+	)
+})
+
+var _ = Describe("pullSecretKeychain", func() {
+	resolve := func(keychain pullSecretKeychain, host string) *authn.AuthConfig {
+		registry, err := name.NewRegistry(host)
+		Expect(err).NotTo(HaveOccurred())
+		authenticator, err := keychain.Resolve(registry)
+		Expect(err).NotTo(HaveOccurred())
+		config, err := authenticator.Authorization()
+		Expect(err).NotTo(HaveOccurred())
+		return config
+	}
+
+	It("reads dockerconfigjson Secrets", func() {
+		keychain := pullSecretKeychain{}
+		keychain.addPullSecret(newDockerConfigSecret(map[string]authn.AuthConfig{
+			"https://index.docker.io/v1/": {Username: "user", Password: "secret"},
+		}))
+		config := resolve(keychain, "docker.io")
+		Expect(config.Username).To(Equal("user"))
+		Expect(config.Password).To(Equal("secret"))
+	})
+
+	It("reads dockercfg Secrets", func() {
+		keychain := pullSecretKeychain{}
+		keychain.addPullSecret(&corev1.Secret{
+			Type: corev1.SecretTypeDockercfg,
+			Data: map[string][]byte{corev1.DockerConfigKey: []byte(`{"ghcr.io":{"username":"user","password":"secret"}}`)},
+		})
+		config := resolve(keychain, "ghcr.io")
+		Expect(config.Username).To(Equal("user"))
+		Expect(config.Password).To(Equal("secret"))
+	})
+
+	It("keeps the credentials of the first Secret", func() {
+		keychain := pullSecretKeychain{}
+		keychain.addPullSecret(newDockerConfigSecret(map[string]authn.AuthConfig{"ghcr.io": {Username: "first"}}))
+		keychain.addPullSecret(newDockerConfigSecret(map[string]authn.AuthConfig{"ghcr.io": {Username: "second"}}))
+		Expect(resolve(keychain, "ghcr.io").Username).To(Equal("first"))
+	})
+
+	It("skips malformed Secrets", func() {
+		keychain := pullSecretKeychain{}
+		keychain.addPullSecret(&corev1.Secret{
+			Type: corev1.SecretTypeDockerConfigJson,
+			Data: map[string][]byte{corev1.DockerConfigJsonKey: []byte("{")},
+		})
+		keychain.addPullSecret(&corev1.Secret{Type: corev1.SecretTypeOpaque})
+		Expect(keychain).To(BeEmpty())
+	})
+
+	It("falls back to anonymous pulls", func() {
+		keychain := pullSecretKeychain{}
+		keychain.addPullSecret(newDockerConfigSecret(map[string]authn.AuthConfig{"ghcr.io": {Username: "user"}}))
+		Expect(resolve(keychain, "quay.io")).To(Equal(&authn.AuthConfig{}))
+	})
+})
+
+var _ = Describe("getImage", func() {
+	var (
+		ctx      context.Context
+		r        *PodInstanciatorReconciler
+		recorder *record.FakeRecorder
+		host     string
+		instance *apiv1alpha1.PodInstanciator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		r, recorder = newImageReconciler()
+		host = startTestRegistry()
+		instance = &apiv1alpha1.PodInstanciator{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       apiv1alpha1.PodInstanciatorSpec{ImageName: host + "/app:1.2.3"},
+		}
+	})
+
+	It("uses the tag as is without pinning", func() {
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.3"))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.Image).To(Equal(image))
+		Expect(instance.Status.ImageDigest).To(BeEmpty())
+		Expect(instance.Status.ImageSource).To(Equal(instance.Spec.ImageName))
+	})
+
+	It("pins the tag to its digest", func() {
+		digest := pushTestImage(host + "/app:1.2.3")
+		instance.Spec.PinImageDigest = true
+
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.3@" + digest))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.ImageDigest).To(Equal(digest))
+	})
+
+	It("keeps the pinned digest when the tag moves", func() {
+		digest := pushTestImage(host + "/app:1.2.3")
+		instance.Spec.PinImageDigest = true
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+
+		pushTestImage(host + "/app:1.2.3")
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.3@" + digest))
+		Expect(update).To(BeNil())
+	})
+
+	It("drops the digest once pinning is turned off", func() {
+		pushTestImage(host + "/app:1.2.3")
+		instance.Spec.PinImageDigest = true
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+
+		instance.Spec.PinImageDigest = false
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.3"))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.ImageHistory).To(BeEmpty())
+		Expect(instance.Status.ImageDigest).To(BeEmpty())
+	})
+
+	It("does not record pinning as an update", func() {
+		digest := pushTestImage(host + "/app:1.2.3")
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+
+		instance.Spec.PinImageDigest = true
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/app:1.2.3@" + digest))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.ImageHistory).To(BeEmpty())
+	})
+
+	It("fails when the tag cannot be resolved", func() {
+		instance.Spec.PinImageDigest = true
+
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("authenticates with the default pull secret before its copy exists", func() {
+		host = startPrivateTestRegistry("user", "secret")
+		digest := pushTestImage(host+"/private:1.0.0", remote.WithAuth(&authn.Basic{Username: "user", Password: "secret"}))
+		secret := newDockerConfigSecret(map[string]authn.AuthConfig{host: {Username: "user", Password: "secret"}})
+		secret.ObjectMeta = metav1.ObjectMeta{Name: "registry", Namespace: "operators"}
+		r, _ = newImageReconciler(secret)
+		r.DefaultPullSecret = &types.NamespacedName{Namespace: "operators", Name: "registry"}
+		instance.Namespace = "team"
+		instance.Spec.ImageName = host + "/private:1.0.0"
+		instance.Spec.PinImageDigest = true
+
+		image, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/private:1.0.0@" + digest))
+	})
+
+	It("fails to resolve private images without credentials", func() {
+		host = startPrivateTestRegistry("user", "secret")
+		pushTestImage(host+"/private:1.0.0", remote.WithAuth(&authn.Basic{Username: "user", Password: "secret"}))
+		instance.Spec.ImageName = host + "/private:1.0.0"
+		instance.Spec.PinImageDigest = true
+
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("uses images referenced by digest as is", func() {
+		digest := pushTestImage(host + "/app:1.2.3")
+		instance.Spec.ImageName = host + "/app@" + digest
+		instance.Spec.PinImageDigest = true
+		instance.Spec.ImageUpdatePolicy.Strategy = apiv1alpha1.ImageUpdatePatch
+
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(instance.Spec.ImageName))
+		Expect(update).To(BeNil())
+		Expect(instance.Status.ImageDigest).To(Equal(digest))
+		Expect(instance.Status.LastImagePollTime).To(BeNil())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("starts over when the image name changes", func() {
+		pushTestImage(host + "/app:1.2.3")
+		pushTestImage(host + "/app:1.2.4")
+		instance.Spec.ImageUpdatePolicy.Strategy = apiv1alpha1.ImageUpdatePatch
+		_, _, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.Status.Image).To(Equal(host + "/app:1.2.4"))
+
+		pushTestImage(host + "/other:2.0.0")
+		pushTestImage(host + "/other:2.0.1")
+		instance.Spec.ImageName = host + "/other:2.0.0"
+		image, update, err := getImage(r, ctx, instance)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(host + "/other:2.0.1"))
+		Expect(update.From).To(Equal(host + "/other:2.0.0"))
+		Expect(instance.Status.ImageSource).To(Equal(instance.Spec.ImageName))
+		Expect(instance.Status.ImageHistory).To(HaveLen(2))
+	})
+})
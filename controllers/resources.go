@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// getResources fills the requests and limits a container does not set with
+// the operator defaults. A default is skipped when it conflicts with the spec,
+// e.g. a default limit lower than the request of the container.
+func getResources(spec corev1.ResourceRequirements, defaults corev1.ResourceRequirements) corev1.ResourceRequirements {
+	resources := *spec.DeepCopy()
+	for name, limit := range defaults.Limits {
+		if _, found := resources.Limits[name]; found {
+			continue
+		}
+		if request, found := resources.Requests[name]; found && request.Cmp(limit) > 0 {
+			continue
+		}
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Limits[name] = limit
+	}
+	for name, request := range defaults.Requests {
+		if _, found := resources.Requests[name]; found {
+			continue
+		}
+		if limit, found := resources.Limits[name]; found && request.Cmp(limit) > 0 {
+			continue
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Requests[name] = request
+	}
+	return resources
+}
+
+func validateResources(resources corev1.ResourceRequirements) error {
+	names := make([]string, 0, len(resources.Requests))
+	for name := range resources.Requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request := resources.Requests[corev1.ResourceName(name)]
+		limit, found := resources.Limits[corev1.ResourceName(name)]
+		if found && request.Cmp(limit) > 0 {
+			return fmt.Errorf("%s request %s exceeds its limit %s", name, request.String(), limit.String())
+		}
+	}
+	return nil
+}
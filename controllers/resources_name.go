@@ -6,6 +6,30 @@ func getPodName(instance *apiv1alpha1.PodInstanciator) string {
 	return instance.Name + "-pod"
 }
 
+func getDeploymentName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-deployment"
+}
+
+func getStatefulSetName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-statefulset"
+}
+
+func getJobName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-job"
+}
+
+func getCronJobName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-cronjob"
+}
+
+func getConfigMapName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-config"
+}
+
+func getPersistentVolumeClaimName(instance *apiv1alpha1.PodInstanciator, volume apiv1alpha1.Volume) string {
+	return instance.Name + "-" + volume.Name + "-pvc"
+}
+
 func getServiceName(instance *apiv1alpha1.PodInstanciator) string {
 	return instance.Name + "-svc"
 }
@@ -14,6 +38,25 @@ func getIngressName(instance *apiv1alpha1.PodInstanciator) string {
 	return instance.Name + "-ingress"
 }
 
+func getHTTPRouteName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-httproute"
+}
+
+func getGRPCRouteName(instance *apiv1alpha1.PodInstanciator) string {
+	return instance.Name + "-grpcroute"
+}
+
+func getTCPRouteName(instance *apiv1alpha1.PodInstanciator, port apiv1alpha1.Port) string {
+	return instance.Name + "-" + port.PortName + "-tcproute"
+}
+
+func getTLSSecretName(instance *apiv1alpha1.PodInstanciator) string {
+	if tls := instance.Spec.Ingress.TLS; tls != nil && tls.SecretName != "" {
+		return tls.SecretName
+	}
+	return instance.Name + "-tls"
+}
+
 func getIngressPathName(port apiv1alpha1.Port) string {
 	return "/" + port.PortName
 }
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+var spreadTopologyKeys = map[apiv1alpha1.SpreadMode]string{
+	apiv1alpha1.SpreadZone: corev1.LabelTopologyZone,
+	apiv1alpha1.SpreadNode: corev1.LabelHostname,
+}
+
+func isReplicated(instance *apiv1alpha1.PodInstanciator) bool {
+	kind := getWorkloadKind(instance)
+	return kind == apiv1alpha1.WorkloadDeployment || kind == apiv1alpha1.WorkloadStatefulSet
+}
+
+// createTopologySpreadConstraints adds the constraint of the spread shorthand
+// to the declared ones. It only skews the scheduling, so that replicas are not
+// left pending when the topology has too few domains.
+func createTopologySpreadConstraints(instance *apiv1alpha1.PodInstanciator) []corev1.TopologySpreadConstraint {
+	constraints := instance.Spec.Scheduling.TopologySpreadConstraints
+	topologyKey, found := spreadTopologyKeys[instance.Spec.Scheduling.Spread]
+	if !found || !isReplicated(instance) {
+		return constraints
+	}
+	for _, constraint := range constraints {
+		if constraint.TopologyKey == topologyKey {
+			return constraints
+		}
+	}
+	return append(append([]corev1.TopologySpreadConstraint{}, constraints...), corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: corev1.ScheduleAnyway,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: getSelectorLabels(instance),
+		},
+	})
+}
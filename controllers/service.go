@@ -2,20 +2,106 @@ package controllers
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// hasService reports whether a Service is rendered. StatefulSets always get
+// their governing Service, which gives their Pods a stable DNS name.
+func hasService(instance *apiv1alpha1.PodInstanciator) bool {
+	return hasPorts(instance) || getWorkloadKind(instance) == apiv1alpha1.WorkloadStatefulSet
+}
+
+// getServiceType returns the type of the Service. Only headless Services may
+// have no ports.
+func getServiceType(instance *apiv1alpha1.PodInstanciator) apiv1alpha1.ServiceType {
+	if !hasPorts(instance) {
+		return apiv1alpha1.ServiceTypeHeadless
+	}
+	if instance.Spec.Service.Type != "" {
+		return instance.Spec.Service.Type
+	}
+	if getWorkloadKind(instance) == apiv1alpha1.WorkloadStatefulSet {
+		return apiv1alpha1.ServiceTypeHeadless
+	}
+	return apiv1alpha1.ServiceTypeClusterIP
+}
+
+func hasNodePorts(instance *apiv1alpha1.PodInstanciator) bool {
+	serviceType := getServiceType(instance)
+	return serviceType == apiv1alpha1.ServiceTypeNodePort || serviceType == apiv1alpha1.ServiceTypeLoadBalancer
+}
+
+func createServicePorts(instance *apiv1alpha1.PodInstanciator) []corev1.ServicePort {
+	specPorts := getPorts(instance)
+	ports := make([]corev1.ServicePort, len(specPorts))
+	for i, port := range specPorts {
+		ports[i] = corev1.ServicePort{
+			Name:       port.PortName,
+			Port:       port.PortNumber,
+			TargetPort: intstr.FromString(port.PortName),
+			Protocol:   getProtocol(port),
+		}
+		if hasNodePorts(instance) {
+			ports[i].NodePort = port.NodePort
+		}
+	}
+	return ports
+}
+
+func createServiceSpec(instance *apiv1alpha1.PodInstanciator) corev1.ServiceSpec {
+	config := instance.Spec.Service
+	spec := corev1.ServiceSpec{
+		Type:            corev1.ServiceTypeClusterIP,
+		Ports:           createServicePorts(instance),
+		Selector:        getSelectorLabels(instance),
+		SessionAffinity: config.SessionAffinity,
+	}
+	switch getServiceType(instance) {
+	case apiv1alpha1.ServiceTypeHeadless:
+		spec.ClusterIP = corev1.ClusterIPNone
+	case apiv1alpha1.ServiceTypeNodePort:
+		spec.Type = corev1.ServiceTypeNodePort
+		spec.ExternalTrafficPolicy = config.ExternalTrafficPolicy
+	case apiv1alpha1.ServiceTypeLoadBalancer:
+		spec.Type = corev1.ServiceTypeLoadBalancer
+		spec.ExternalTrafficPolicy = config.ExternalTrafficPolicy
+		spec.LoadBalancerClass = config.LoadBalancerClass
+		spec.LoadBalancerSourceRanges = config.LoadBalancerSourceRanges
+	}
+	return spec
+}
+
 func createService(instance *apiv1alpha1.PodInstanciator) *corev1.Service {
 	return &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      getServiceName(instance),
-			Namespace: instance.Namespace,
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
 		},
-		Spec: corev1.ServiceSpec{
-			Ports:     []corev1.ServicePort{},
-			Selector:  map[string]string{"app": getPodName(instance)},
-			ClusterIP: "None",
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getServiceName(instance),
+			Namespace:   instance.Namespace,
+			Labels:      getLabels(instance),
+			Annotations: instance.Spec.Service.Annotations,
 		},
+		Spec: createServiceSpec(instance),
+	}
+}
+
+// serviceNeedsRecreate reports whether the Service switches between headless
+// and not headless, or changes its load balancer class, which are both
+// immutable.
+func serviceNeedsRecreate(resource client.Object, foundResource client.Object) bool {
+	svc := resource.(*corev1.Service)
+	found := foundResource.(*corev1.Service)
+	if (svc.Spec.ClusterIP == corev1.ClusterIPNone) != (found.Spec.ClusterIP == corev1.ClusterIPNone) {
+		return true
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer || found.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		return false
 	}
+	return !equality.Semantic.DeepEqual(svc.Spec.LoadBalancerClass, found.Spec.LoadBalancerClass)
 }
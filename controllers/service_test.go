@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+var _ = Describe("hasService", func() {
+	newInstance := func(kind apiv1alpha1.WorkloadKind, ports ...apiv1alpha1.Port) *apiv1alpha1.PodInstanciator {
+		return &apiv1alpha1.PodInstanciator{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec: apiv1alpha1.PodInstanciatorSpec{
+				ImageName: "nginx",
+				Ports:     ports,
+				Workload:  apiv1alpha1.WorkloadSpec{Kind: kind},
+			},
+		}
+	}
+
+	DescribeTable("renders a Service for the workloads which need one",
+		func(kind apiv1alpha1.WorkloadKind, ports []apiv1alpha1.Port, expected bool) {
+			Expect(hasService(newInstance(kind, ports...))).To(Equal(expected))
+		},
+		Entry("StatefulSet without ports", apiv1alpha1.WorkloadStatefulSet, nil, true),
+		Entry("StatefulSet with ports", apiv1alpha1.WorkloadStatefulSet, []apiv1alpha1.Port{{PortName: "http", PortNumber: 80}}, true),
+		Entry("Deployment without ports", apiv1alpha1.WorkloadDeployment, nil, false),
+		Entry("Deployment with ports", apiv1alpha1.WorkloadDeployment, []apiv1alpha1.Port{{PortName: "http", PortNumber: 80}}, true),
+		Entry("Job without ports", apiv1alpha1.WorkloadJob, nil, false),
+		Entry("CronJob without ports", apiv1alpha1.WorkloadCronJob, nil, false),
+		Entry("Pod without ports", apiv1alpha1.WorkloadPod, nil, false),
+	)
+
+	It("renders the governing Service of a StatefulSet without ports", func() {
+		instance := newInstance(apiv1alpha1.WorkloadStatefulSet)
+		instance.Spec.Service.Type = apiv1alpha1.ServiceTypeClusterIP
+
+		service := createService(instance)
+		Expect(service.Name).To(Equal(createStatefulSet(instance, createPodTemplate(&PodInstanciatorReconciler{}, instance)).Spec.ServiceName))
+		Expect(service.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(service.Spec.Ports).To(BeEmpty())
+		Expect(service.Spec.Selector).To(Equal(getSelectorLabels(instance)))
+	})
+})
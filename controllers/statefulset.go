@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func createVolumeClaimTemplates(instance *apiv1alpha1.PodInstanciator) []corev1.PersistentVolumeClaim {
+	templates := instance.Spec.Workload.VolumeClaimTemplates
+	claims := make([]corev1.PersistentVolumeClaim, len(templates))
+	for i, template := range templates {
+		claims[i] = corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   template.Name,
+				Labels: getLabels(instance),
+			},
+			Spec: createClaimSpec(template.ClaimSpec),
+		}
+	}
+	return claims
+}
+
+func createVolumeClaimMounts(instance *apiv1alpha1.PodInstanciator) []corev1.VolumeMount {
+	templates := instance.Spec.Workload.VolumeClaimTemplates
+	mounts := make([]corev1.VolumeMount, len(templates))
+	for i, template := range templates {
+		mounts[i] = corev1.VolumeMount{
+			Name:      template.Name,
+			MountPath: template.MountPath,
+		}
+	}
+	return mounts
+}
+
+func createStatefulSet(instance *apiv1alpha1.PodInstanciator, podTemplate corev1.PodTemplateSpec) *appsv1.StatefulSet {
+	replicas := getReplicas(instance)
+	template := *podTemplate.DeepCopy()
+	template.Spec.Containers[0].VolumeMounts = append(template.Spec.Containers[0].VolumeMounts, createVolumeClaimMounts(instance)...)
+
+	whenDeleted := appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+	if instance.Spec.Workload.VolumeClaimDeletionPolicy == apiv1alpha1.DeletionPolicyDelete {
+		whenDeleted = appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+	}
+
+	spec := appsv1.StatefulSetSpec{
+		Replicas:    &replicas,
+		ServiceName: getServiceName(instance),
+		Selector: &metav1.LabelSelector{
+			MatchLabels: getSelectorLabels(instance),
+		},
+		Template:             template,
+		VolumeClaimTemplates: createVolumeClaimTemplates(instance),
+		PodManagementPolicy:  instance.Spec.Workload.PodManagementPolicy,
+		RevisionHistoryLimit: instance.Spec.Workload.RevisionHistoryLimit,
+		PersistentVolumeClaimRetentionPolicy: &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+			WhenDeleted: whenDeleted,
+			WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		},
+	}
+	immutableSpec := appsv1.StatefulSetSpec{
+		ServiceName:          spec.ServiceName,
+		Selector:             spec.Selector,
+		VolumeClaimTemplates: spec.VolumeClaimTemplates,
+		PodManagementPolicy:  spec.PodManagementPolicy,
+	}
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "StatefulSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getStatefulSetName(instance),
+			Namespace:   instance.Namespace,
+			Labels:      getLabels(instance),
+			Annotations: setSpecHash(nil, immutableSpec),
+		},
+		Spec: spec,
+	}
+}
+
+// statefulSetNeedsRecreate reports whether one of the immutable fields of the
+// StatefulSet changed. It is recreated without its Pods and claims, which the
+// new StatefulSet adopts.
+func statefulSetNeedsRecreate(resource client.Object, foundResource client.Object) bool {
+	return !hasSameSpecHash(resource.GetAnnotations(), foundResource.GetAnnotations())
+}
+
+func isStatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdatedReplicas == replicas &&
+		statefulSet.Status.ReadyReplicas >= replicas
+}
@@ -0,0 +1,432 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func setCondition(instance *apiv1alpha1.PodInstanciator, conditionType string, ready bool, reason string, message string) {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: instance.Generation,
+	})
+}
+
+// getResource fetches a generated resource, reporting false when it does not
+// exist (yet).
+func getResource(r *PodInstanciatorReconciler, ctx context.Context, name string, namespace string, resource client.Object) (bool, error) {
+	err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, resource)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func updatePodStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, bool, error) {
+	pod := &corev1.Pod{}
+	found, err := getResource(r, ctx, getPodName(instance), instance.Namespace, pod)
+	if err != nil {
+		return false, false, err
+	}
+	instance.Status.Replicas = 1
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "NotFound", "Pod does not exist yet")
+		return false, false, nil
+	}
+	instance.Status.WorkloadName = pod.Name
+	instance.Status.ConfigHash = pod.Annotations[configHashAnnotation]
+	instance.Status.PodName = pod.Name
+	instance.Status.PodIP = pod.Status.PodIP
+
+	ready := isPodReady(pod)
+	switch {
+	case pod.DeletionTimestamp != nil:
+		ready = false
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "Recreating", "Pod is being recreated")
+	case ready:
+		instance.Status.ReadyReplicas = 1
+		setCondition(instance, apiv1alpha1.ConditionPodReady, true, "PodReady", "Pod is ready")
+	default:
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "PodNotReady", "Pod is "+string(pod.Status.Phase))
+	}
+	return ready, pod.Status.Phase == corev1.PodFailed, nil
+}
+
+func updateDeploymentStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, bool, error) {
+	deployment := &appsv1.Deployment{}
+	found, err := getResource(r, ctx, getDeploymentName(instance), instance.Namespace, deployment)
+	if err != nil {
+		return false, false, err
+	}
+	instance.Status.Replicas = getReplicas(instance)
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "NotFound", "Deployment does not exist yet")
+		return false, false, nil
+	}
+	instance.Status.Replicas = getDeploymentReplicas(deployment)
+	instance.Status.WorkloadName = deployment.Name
+	instance.Status.ConfigHash = deployment.Spec.Template.Annotations[configHashAnnotation]
+	instance.Status.ReadyReplicas = deployment.Status.ReadyReplicas
+
+	message := fmt.Sprintf("%d/%d replicas are ready", deployment.Status.ReadyReplicas, instance.Status.Replicas)
+	if !isDeploymentReady(deployment) {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "ReplicasNotReady", message)
+		return false, isDeploymentFailed(deployment), nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionPodReady, true, "ReplicasReady", message)
+	return true, false, nil
+}
+
+func updateStatefulSetStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	found, err := getResource(r, ctx, getStatefulSetName(instance), instance.Namespace, statefulSet)
+	if err != nil {
+		return false, err
+	}
+	instance.Status.Replicas = getReplicas(instance)
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "NotFound", "StatefulSet does not exist yet")
+		return false, nil
+	}
+	instance.Status.WorkloadName = statefulSet.Name
+	instance.Status.ConfigHash = statefulSet.Spec.Template.Annotations[configHashAnnotation]
+	instance.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
+
+	message := fmt.Sprintf("%d/%d replicas are ready", statefulSet.Status.ReadyReplicas, instance.Status.Replicas)
+	if !isStatefulSetReady(statefulSet) {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "ReplicasNotReady", message)
+		return false, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionPodReady, true, "ReplicasReady", message)
+	return true, nil
+}
+
+func updateJobStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, bool, error) {
+	job := &batchv1.Job{}
+	found, err := getResource(r, ctx, getJobName(instance), instance.Namespace, job)
+	if err != nil {
+		return false, false, err
+	}
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "NotFound", "Job does not exist yet")
+		return false, false, nil
+	}
+	instance.Status.WorkloadName = job.Name
+	instance.Status.ConfigHash = job.Spec.Template.Annotations[configHashAnnotation]
+	instance.Status.Active = job.Status.Active
+	instance.Status.Succeeded = job.Status.Succeeded
+	instance.Status.Failed = job.Status.Failed
+	instance.Status.LastCompletionTime = job.Status.CompletionTime
+
+	switch {
+	case isJobComplete(job):
+		setCondition(instance, apiv1alpha1.ConditionPodReady, true, "JobComplete", "Job succeeded")
+		return true, false, nil
+	case isJobFailed(job):
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "JobFailed", "Job failed")
+		return false, true, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionPodReady, false, "JobRunning", fmt.Sprintf("%d Pods are running", job.Status.Active))
+	return false, false, nil
+}
+
+// updateCronJobStatus reports the CronJob as ready once it is scheduled, and
+// counts the outcome of the Jobs it keeps in its history.
+func updateCronJobStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	cronJob := &batchv1.CronJob{}
+	found, err := getResource(r, ctx, getCronJobName(instance), instance.Namespace, cronJob)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "NotFound", "CronJob does not exist yet")
+		return false, nil
+	}
+	instance.Status.WorkloadName = cronJob.Name
+	instance.Status.ConfigHash = cronJob.Spec.JobTemplate.Spec.Template.Annotations[configHashAnnotation]
+	instance.Status.Active = int32(len(cronJob.Status.Active))
+	instance.Status.LastCompletionTime = cronJob.Status.LastSuccessfulTime
+
+	jobs := &batchv1.JobList{}
+	err = r.List(ctx, jobs, client.InNamespace(instance.Namespace), client.MatchingLabels(getSelectorLabels(instance)))
+	if err != nil {
+		return false, err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !metav1.IsControlledBy(job, cronJob) {
+			continue
+		}
+		if isJobComplete(job) {
+			instance.Status.Succeeded++
+		} else if isJobFailed(job) {
+			instance.Status.Failed++
+		}
+	}
+
+	if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+		setCondition(instance, apiv1alpha1.ConditionPodReady, false, "Suspended", "CronJob is suspended")
+		return false, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionPodReady, true, "Scheduled", "CronJob is scheduled")
+	return true, nil
+}
+
+// updateWorkloadStatus reports the state of the workload, and whether it
+// failed, according to the workload kind.
+func updateWorkloadStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, bool, error) {
+	instance.Status.WorkloadName = ""
+	instance.Status.ConfigHash = ""
+	instance.Status.Replicas = 0
+	instance.Status.ReadyReplicas = 0
+	instance.Status.PodName = ""
+	instance.Status.PodIP = ""
+	instance.Status.Active = 0
+	instance.Status.Succeeded = 0
+	instance.Status.Failed = 0
+	instance.Status.LastCompletionTime = nil
+
+	switch getWorkloadKind(instance) {
+	case apiv1alpha1.WorkloadStatefulSet:
+		ready, err := updateStatefulSetStatus(r, ctx, instance)
+		return ready, false, err
+	case apiv1alpha1.WorkloadJob:
+		return updateJobStatus(r, ctx, instance)
+	case apiv1alpha1.WorkloadCronJob:
+		ready, err := updateCronJobStatus(r, ctx, instance)
+		return ready, false, err
+	case apiv1alpha1.WorkloadPod:
+		return updatePodStatus(r, ctx, instance)
+	}
+	return updateDeploymentStatus(r, ctx, instance)
+}
+
+func updateServiceStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	if !hasService(instance) {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, apiv1alpha1.ConditionServiceReady)
+		instance.Status.ServiceName = ""
+		return true, nil
+	}
+	svc := &corev1.Service{}
+	found, err := getResource(r, ctx, getServiceName(instance), instance.Namespace, svc)
+	if err != nil {
+		return false, err
+	}
+	instance.Status.ServiceName = ""
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionServiceReady, false, "NotFound", "Service does not exist yet")
+		return false, nil
+	}
+	instance.Status.ServiceName = svc.Name
+
+	readyEndpoints, err := countReadyEndpoints(r, ctx, svc)
+	if err != nil {
+		return false, err
+	}
+	if readyEndpoints == 0 {
+		setCondition(instance, apiv1alpha1.ConditionServiceReady, false, "NoReadyEndpoints", "Service has no ready endpoints")
+		return false, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionServiceReady, true, "EndpointsReady", fmt.Sprintf("Service has %d ready endpoints", readyEndpoints))
+	return true, nil
+}
+
+func countReadyEndpoints(r *PodInstanciatorReconciler, ctx context.Context, svc *corev1.Service) (int, error) {
+	slices := &discoveryv1.EndpointSliceList{}
+	err := r.List(ctx, slices, client.InNamespace(svc.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: svc.Name})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func updateIngressStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	ingress := &networkingv1.Ingress{}
+	found, err := getResource(r, ctx, getIngressName(instance), instance.Namespace, ingress)
+	if err != nil {
+		return false, err
+	}
+	instance.Status.IngressName = ""
+	instance.Status.URLs = nil
+	if !found {
+		setCondition(instance, apiv1alpha1.ConditionIngressReady, false, "NotFound", "Ingress does not exist yet")
+		return false, nil
+	}
+	instance.Status.IngressName = ingress.Name
+	instance.Status.URLs = getIngressURLs(instance, ingress)
+	if len(ingress.Status.LoadBalancer.Ingress) == 0 {
+		setCondition(instance, apiv1alpha1.ConditionIngressReady, false, "AddressPending", "Ingress has no address yet")
+		return false, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionIngressReady, true, "AddressAssigned", "Ingress has an address")
+	return true, nil
+}
+
+func getRouteParents(route client.Object) []gatewayv1beta1.RouteParentStatus {
+	switch route := route.(type) {
+	case *gatewayv1beta1.HTTPRoute:
+		return route.Status.Parents
+	case *gatewayv1alpha2.GRPCRoute:
+		return route.Status.Parents
+	case *gatewayv1alpha2.TCPRoute:
+		return route.Status.Parents
+	}
+	return nil
+}
+
+func updateRouteStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	var routes []client.Object
+	if len(getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolHTTP)) > 0 {
+		routes = append(routes, &gatewayv1beta1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: getHTTPRouteName(instance)}})
+	}
+	if len(getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolGRPC)) > 0 {
+		routes = append(routes, &gatewayv1alpha2.GRPCRoute{ObjectMeta: metav1.ObjectMeta{Name: getGRPCRouteName(instance)}})
+	}
+	for _, port := range getPortsByAppProtocol(instance, apiv1alpha1.AppProtocolTCP) {
+		routes = append(routes, &gatewayv1alpha2.TCPRoute{ObjectMeta: metav1.ObjectMeta{Name: getTCPRouteName(instance, port)}})
+	}
+
+	instance.Status.RouteNames = nil
+	instance.Status.URLs = nil
+	accepted := true
+	for _, route := range routes {
+		found, err := getResource(r, ctx, route.GetName(), instance.Namespace, route)
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			setCondition(instance, apiv1alpha1.ConditionRouteReady, false, "NotFound", route.GetName()+" does not exist yet")
+			return false, nil
+		}
+		instance.Status.RouteNames = append(instance.Status.RouteNames, route.GetName())
+		if httpRoute, ok := route.(*gatewayv1beta1.HTTPRoute); ok {
+			instance.Status.URLs = getGatewayURLs(instance, httpRoute)
+		}
+		accepted = accepted && isRouteAccepted(getRouteParents(route))
+	}
+	if !accepted {
+		setCondition(instance, apiv1alpha1.ConditionRouteReady, false, "NotAccepted", "some routes are not accepted by their Gateway yet")
+		return false, nil
+	}
+	setCondition(instance, apiv1alpha1.ConditionRouteReady, true, "Accepted", "all routes are accepted by their Gateway")
+	return true, nil
+}
+
+// updateExposureStatus reports the state of the resources exposing the ports
+// according to the exposure mode, and clears the state of the other modes.
+func updateExposureStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) (bool, error) {
+	mode := getExposureMode(instance, r.DefaultExposure)
+	if !hasPorts(instance) {
+		mode = apiv1alpha1.ExposureNone
+	}
+	if mode != apiv1alpha1.ExposureIngress {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, apiv1alpha1.ConditionIngressReady)
+		instance.Status.IngressName = ""
+		instance.Status.TLS = nil
+	}
+	if mode != apiv1alpha1.ExposureGateway {
+		meta.RemoveStatusCondition(&instance.Status.Conditions, apiv1alpha1.ConditionRouteReady)
+		instance.Status.RouteNames = nil
+	}
+
+	switch mode {
+	case apiv1alpha1.ExposureIngress:
+		ready, err := updateIngressStatus(r, ctx, instance)
+		if err != nil {
+			return false, err
+		}
+		return ready, updateTLSStatus(r, ctx, instance)
+	case apiv1alpha1.ExposureGateway:
+		return updateRouteStatus(r, ctx, instance)
+	}
+	instance.Status.URLs = nil
+	return true, nil
+}
+
+// updateStatus refreshes the status of the instance from its generated
+// resources. conflicts lists the resources whose rendered fields were owned by
+// another field manager.
+func updateStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator, conflicts []string) error {
+	podReady, podFailed, err := updateWorkloadStatus(r, ctx, instance)
+	if err != nil {
+		return err
+	}
+	serviceReady, err := updateServiceStatus(r, ctx, instance)
+	if err != nil {
+		return err
+	}
+	exposureReady, err := updateExposureStatus(r, ctx, instance)
+	if err != nil {
+		return err
+	}
+
+	degraded := len(conflicts) > 0
+	if degraded {
+		setCondition(instance, apiv1alpha1.ConditionDegraded, true, "FieldConflict", strings.Join(conflicts, "; "))
+	} else {
+		setCondition(instance, apiv1alpha1.ConditionDegraded, false, "ResourcesApplied", "all resources are applied")
+	}
+
+	ready := podReady && serviceReady && exposureReady && !degraded
+	if ready {
+		setCondition(instance, apiv1alpha1.ConditionReady, true, "ResourcesReady", "all resources are ready")
+	} else {
+		setCondition(instance, apiv1alpha1.ConditionReady, false, "ResourcesNotReady", "some resources are not ready")
+	}
+
+	switch {
+	case degraded:
+		instance.Status.Phase = apiv1alpha1.PhaseDegraded
+	case podFailed:
+		instance.Status.Phase = apiv1alpha1.PhaseFailed
+	case ready && getWorkloadKind(instance) == apiv1alpha1.WorkloadJob:
+		instance.Status.Phase = apiv1alpha1.PhaseSucceeded
+	case ready, instance.Status.Active > 0:
+		instance.Status.Phase = apiv1alpha1.PhaseRunning
+	default:
+		instance.Status.Phase = apiv1alpha1.PhasePending
+	}
+	instance.Status.ObservedGeneration = instance.Generation
+
+	return r.Status().Update(ctx, instance)
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// getCertificateExpiry reads the expiration date of the first certificate of
+// a TLS Secret, returning nil when it cannot be parsed.
+func getCertificateExpiry(secret *corev1.Secret) *metav1.Time {
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return nil
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	notAfter := metav1.NewTime(certificate.NotAfter)
+	return &notAfter
+}
+
+func updateTLSStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator) error {
+	if instance.Spec.Ingress.TLS == nil {
+		instance.Status.TLS = nil
+		return nil
+	}
+	secret := &corev1.Secret{}
+	found, err := getResource(r, ctx, getTLSSecretName(instance), instance.Namespace, secret)
+	if err != nil {
+		return err
+	}
+	instance.Status.TLS = &apiv1alpha1.TLSStatus{
+		SecretName:   getTLSSecretName(instance),
+		SecretExists: found,
+	}
+	if found {
+		instance.Status.TLS.NotAfter = getCertificateExpiry(secret)
+	}
+	return nil
+}
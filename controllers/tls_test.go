@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// createSelfSignedCertificate returns the PEM encoded certificate and key of a
+// self-signed certificate expiring at notAfter.
+func createSelfSignedCertificate(notAfter time.Time) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "app.example.com"},
+		DNSNames:     []string{"app.example.com"},
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+var _ = Describe("updateTLSStatus", func() {
+	var (
+		ctx      context.Context
+		r        *PodInstanciatorReconciler
+		instance *apiv1alpha1.PodInstanciator
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		r = &PodInstanciatorReconciler{
+			Client: fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+			Scheme: scheme.Scheme,
+		}
+		instance = &apiv1alpha1.PodInstanciator{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls-app", Namespace: "default"},
+			Spec: apiv1alpha1.PodInstanciatorSpec{
+				Ingress: apiv1alpha1.IngressSpec{TLS: &apiv1alpha1.IngressTLS{}},
+			},
+		}
+	})
+
+	createTLSSecret := func(name string, cert []byte, key []byte) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: key},
+		}
+		Expect(r.Create(ctx, secret)).To(Succeed())
+	}
+
+	It("reports the expiration of the certificate", func() {
+		notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+		cert, key := createSelfSignedCertificate(notAfter)
+		createTLSSecret("tls-app-tls", cert, key)
+
+		Expect(updateTLSStatus(r, ctx, instance)).To(Succeed())
+		Expect(instance.Status.TLS).NotTo(BeNil())
+		Expect(instance.Status.TLS.SecretName).To(Equal("tls-app-tls"))
+		Expect(instance.Status.TLS.SecretExists).To(BeTrue())
+		Expect(instance.Status.TLS.NotAfter).NotTo(BeNil())
+		Expect(instance.Status.TLS.NotAfter.Time.Equal(notAfter)).To(BeTrue())
+	})
+
+	It("reads the declared Secret", func() {
+		notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		cert, key := createSelfSignedCertificate(notAfter)
+		createTLSSecret("custom-tls", cert, key)
+		instance.Spec.Ingress.TLS.SecretName = "custom-tls"
+
+		Expect(updateTLSStatus(r, ctx, instance)).To(Succeed())
+		Expect(instance.Status.TLS.SecretName).To(Equal("custom-tls"))
+		Expect(instance.Status.TLS.SecretExists).To(BeTrue())
+		Expect(instance.Status.TLS.NotAfter.Time.Equal(notAfter)).To(BeTrue())
+	})
+
+	It("reports a missing Secret", func() {
+		Expect(updateTLSStatus(r, ctx, instance)).To(Succeed())
+		Expect(instance.Status.TLS.SecretExists).To(BeFalse())
+		Expect(instance.Status.TLS.NotAfter).To(BeNil())
+	})
+
+	It("leaves the expiration unset when the certificate cannot be parsed", func() {
+		createTLSSecret("tls-app-tls", []byte("not a certificate"), []byte("not a key"))
+
+		Expect(updateTLSStatus(r, ctx, instance)).To(Succeed())
+		Expect(instance.Status.TLS.SecretExists).To(BeTrue())
+		Expect(instance.Status.TLS.NotAfter).To(BeNil())
+	})
+
+	It("clears the status without TLS", func() {
+		instance.Spec.Ingress.TLS = nil
+		instance.Status.TLS = &apiv1alpha1.TLSStatus{SecretName: "tls-app-tls"}
+
+		Expect(updateTLSStatus(r, ctx, instance)).To(Succeed())
+		Expect(instance.Status.TLS).To(BeNil())
+	})
+})
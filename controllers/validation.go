@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+// validateSpec checks the rules the CRD schema cannot express on the spec and
+// the rendered Pod template.
+func validateSpec(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) error {
+	if err := validateImageUpdatePolicy(instance); err != nil {
+		return err
+	}
+	if err := validateWorkload(instance); err != nil {
+		return err
+	}
+	if err := validateFiles(instance); err != nil {
+		return err
+	}
+	if err := validateVolumes(instance); err != nil {
+		return err
+	}
+	containerNames := map[string]bool{}
+	portNames := map[string]string{}
+	containers := append(append([]corev1.Container{}, template.Spec.InitContainers...), template.Spec.Containers...)
+	for _, container := range containers {
+		if containerNames[container.Name] {
+			return fmt.Errorf("container name %s is used more than once", container.Name)
+		}
+		containerNames[container.Name] = true
+		for _, port := range container.Ports {
+			if other, found := portNames[port.Name]; found {
+				return fmt.Errorf("port name %s is used by both containers %s and %s", port.Name, other, container.Name)
+			}
+			portNames[port.Name] = container.Name
+		}
+		if err := validateResources(container.Resources); err != nil {
+			return fmt.Errorf("container %s: %w", container.Name, err)
+		}
+	}
+	return nil
+}
+
+// updateInvalidStatus reports an invalid spec, in which case no resource is
+// applied until the spec is fixed.
+func updateInvalidStatus(r *PodInstanciatorReconciler, ctx context.Context, instance *apiv1alpha1.PodInstanciator, err error) error {
+	setCondition(instance, apiv1alpha1.ConditionReady, false, "InvalidSpec", err.Error())
+	instance.Status.Phase = apiv1alpha1.PhaseFailed
+	instance.Status.ObservedGeneration = instance.Generation
+	return r.Status().Update(ctx, instance)
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func createClaimSpec(claim apiv1alpha1.ClaimSpec) corev1.PersistentVolumeClaimSpec {
+	accessModes := claim.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return corev1.PersistentVolumeClaimSpec{
+		AccessModes:      accessModes,
+		StorageClassName: claim.StorageClassName,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: claim.Size},
+		},
+	}
+}
+
+// isSingleNodeClaim reports whether the claim can only be mounted by the Pods
+// of a single node, as ReadWriteOnce claims.
+func isSingleNodeClaim(claim apiv1alpha1.ClaimSpec) bool {
+	for _, mode := range createClaimSpec(claim).AccessModes {
+		if mode == corev1.ReadWriteMany || mode == corev1.ReadOnlyMany {
+			return false
+		}
+	}
+	return true
+}
+
+// hasSingleNodeClaim reports whether one of the generated claims of the
+// instance can only be mounted by the Pods of a single node.
+func hasSingleNodeClaim(instance *apiv1alpha1.PodInstanciator) bool {
+	for _, volume := range instance.Spec.Volumes {
+		if volume.GeneratedClaim != nil && isSingleNodeClaim(volume.GeneratedClaim.ClaimSpec) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVolumes checks that each volume has exactly one source, and that the
+// generated claims can be mounted by all the replicas.
+func validateVolumes(instance *apiv1alpha1.PodInstanciator) error {
+	for _, volume := range instance.Spec.Volumes {
+		sources := 0
+		if volume.EmptyDir != nil {
+			sources++
+		}
+		if volume.PersistentVolumeClaim != nil {
+			sources++
+		}
+		if volume.GeneratedClaim != nil {
+			sources++
+		}
+		if volume.ConfigMap != nil {
+			sources++
+		}
+		if volume.Secret != nil {
+			sources++
+		}
+		if sources != 1 {
+			return fmt.Errorf("volume %s has %d sources, exactly one has to be set", volume.Name, sources)
+		}
+	}
+	if isReplicated(instance) && getReplicas(instance) > 1 && hasSingleNodeClaim(instance) {
+		return fmt.Errorf("%d replicas cannot share a generated claim without a ReadWriteMany or ReadOnlyMany access mode", getReplicas(instance))
+	}
+	return nil
+}
+
+func createPersistentVolumeClaim(instance *apiv1alpha1.PodInstanciator, volume apiv1alpha1.Volume) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPersistentVolumeClaimName(instance, volume),
+			Namespace: instance.Namespace,
+			Labels:    getLabels(instance),
+		},
+		Spec: createClaimSpec(volume.GeneratedClaim.ClaimSpec),
+	}
+}
+
+// createVolumeClaimResources renders the claims generated for the volumes.
+// Retained claims are not owned by the instance, so that they survive it.
+func createVolumeClaimResources(instance *apiv1alpha1.PodInstanciator) []generatedResource {
+	var resources []generatedResource
+	for _, volume := range instance.Spec.Volumes {
+		if volume.GeneratedClaim == nil {
+			continue
+		}
+		resources = append(resources, generatedResource{
+			resource:      createPersistentVolumeClaim(instance, volume),
+			foundResource: &corev1.PersistentVolumeClaim{},
+			retain:        volume.GeneratedClaim.DeletionPolicy == apiv1alpha1.DeletionPolicyRetain,
+		})
+	}
+	return resources
+}
+
+func createVolumes(instance *apiv1alpha1.PodInstanciator) []corev1.Volume {
+	volumes := createFilesVolumes(instance)
+	for _, volume := range instance.Spec.Volumes {
+		source := corev1.VolumeSource{
+			EmptyDir:              volume.EmptyDir,
+			PersistentVolumeClaim: volume.PersistentVolumeClaim,
+			ConfigMap:             volume.ConfigMap,
+			Secret:                volume.Secret,
+		}
+		if volume.GeneratedClaim != nil {
+			source.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: getPersistentVolumeClaimName(instance, volume),
+			}
+		}
+		volumes = append(volumes, corev1.Volume{Name: volume.Name, VolumeSource: source})
+	}
+	return volumes
+}
+
+func createVolumeMounts(instance *apiv1alpha1.PodInstanciator) []corev1.VolumeMount {
+	return append(createFilesVolumeMounts(instance), instance.Spec.VolumeMounts...)
+}
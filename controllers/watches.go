@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+const (
+	// secretIndexKey indexes the PodInstanciators by the Secrets they
+	// reference.
+	secretIndexKey = "spec.secretRefs"
+	// configMapIndexKey indexes the PodInstanciators by the ConfigMaps they
+	// reference.
+	configMapIndexKey = "spec.configMapRefs"
+)
+
+func getReferencedSecrets(instance *apiv1alpha1.PodInstanciator) []string {
+	_, secrets := getConfigReferences(instance)
+	if instance.Spec.Ingress.TLS != nil {
+		secrets = append(secrets, getTLSSecretName(instance))
+	}
+	return secrets
+}
+
+func indexReferencedSecrets(obj client.Object) []string {
+	return getReferencedSecrets(obj.(*apiv1alpha1.PodInstanciator))
+}
+
+func indexReferencedConfigMaps(obj client.Object) []string {
+	configMaps, _ := getConfigReferences(obj.(*apiv1alpha1.PodInstanciator))
+	return configMaps
+}
+
+// findInstancesForSecret maps a Secret to the PodInstanciators referencing it.
+// Changes to the default pull secret are mapped to every instance, and changes
+// to one of its copies to the instances of its namespace.
+func (r *PodInstanciatorReconciler) findInstancesForSecret(secret client.Object) []reconcile.Request {
+	if isDefaultPullSecret(r, secret) {
+		opts := []client.ListOption{client.InNamespace(secret.GetNamespace())}
+		if secret.GetNamespace() == r.DefaultPullSecret.Namespace {
+			opts = nil
+		}
+		return r.findInstances(opts...)
+	}
+	return r.findInstancesByIndex(secretIndexKey, secret)
+}
+
+// findInstancesForConfigMap maps a ConfigMap to the PodInstanciators
+// referencing it.
+func (r *PodInstanciatorReconciler) findInstancesForConfigMap(configMap client.Object) []reconcile.Request {
+	return r.findInstancesByIndex(configMapIndexKey, configMap)
+}
+
+func (r *PodInstanciatorReconciler) findInstancesByIndex(indexKey string, obj client.Object) []reconcile.Request {
+	return r.findInstances(client.InNamespace(obj.GetNamespace()), client.MatchingFields{indexKey: obj.GetName()})
+}
+
+func (r *PodInstanciatorReconciler) findInstances(opts ...client.ListOption) []reconcile.Request {
+	instances := &apiv1alpha1.PodInstanciatorList{}
+	err := r.List(context.Background(), instances, opts...)
+	if err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, len(instances.Items))
+	for i, instance := range instances.Items {
+		requests[i] = reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace},
+		}
+	}
+	return requests
+}
+
+// findInstanceForEndpointSlice maps an EndpointSlice to the PodInstanciator
+// owning its Service, so that the ServiceReady condition follows the endpoints.
+func (r *PodInstanciatorReconciler) findInstanceForEndpointSlice(slice client.Object) []reconcile.Request {
+	serviceName := slice.GetLabels()[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return nil
+	}
+	svc := &corev1.Service{}
+	err := r.Get(context.Background(), types.NamespacedName{Name: serviceName, Namespace: slice.GetNamespace()}, svc)
+	if err != nil {
+		return nil
+	}
+	owner := metav1.GetControllerOf(svc)
+	if owner == nil || owner.APIVersion != apiv1alpha1.GroupVersion.String() || owner.Kind != "PodInstanciator" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: slice.GetNamespace()}},
+	}
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
+)
+
+func getWorkloadKind(instance *apiv1alpha1.PodInstanciator) apiv1alpha1.WorkloadKind {
+	if instance.Spec.Workload.Kind == "" {
+		return apiv1alpha1.WorkloadDeployment
+	}
+	return instance.Spec.Workload.Kind
+}
+
+// validateWorkload checks the fields the workload kind requires.
+func validateWorkload(instance *apiv1alpha1.PodInstanciator) error {
+	if getWorkloadKind(instance) == apiv1alpha1.WorkloadCronJob && instance.Spec.Workload.Schedule == "" {
+		return fmt.Errorf("a %s workload requires a schedule", apiv1alpha1.WorkloadCronJob)
+	}
+	return nil
+}
+
+// createWorkloadResources renders the resources running the Pod template,
+// according to the workload kind of the instance.
+func createWorkloadResources(instance *apiv1alpha1.PodInstanciator, template corev1.PodTemplateSpec) []generatedResource {
+	switch getWorkloadKind(instance) {
+	case apiv1alpha1.WorkloadStatefulSet:
+		return []generatedResource{
+			{resource: createStatefulSet(instance, template), foundResource: &appsv1.StatefulSet{}, needsRecreate: statefulSetNeedsRecreate, orphanOnRecreate: true},
+		}
+	case apiv1alpha1.WorkloadJob:
+		return []generatedResource{
+			{resource: createJob(instance, template), foundResource: &batchv1.Job{}, needsRecreate: jobNeedsRecreate},
+		}
+	case apiv1alpha1.WorkloadCronJob:
+		return []generatedResource{
+			{resource: createCronJob(instance, template), foundResource: &batchv1.CronJob{}},
+		}
+	case apiv1alpha1.WorkloadPod:
+		return []generatedResource{
+			{resource: createPod(instance, template), foundResource: &corev1.Pod{}, needsRecreate: podNeedsRecreate},
+		}
+	}
+	return []generatedResource{
+		{resource: createDeployment(instance, template), foundResource: &appsv1.Deployment{}, needsRecreate: deploymentNeedsRecreate},
+	}
+}
@@ -18,18 +18,26 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"text/template"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
 	apiv1alpha1 "operators/PodInstanciater/api/v1alpha1"
 	"operators/PodInstanciater/controllers"
@@ -44,6 +52,9 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
+	utilruntime.Must(gatewayv1beta1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1alpha2.AddToScheme(scheme))
+
 	utilruntime.Must(apiv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
@@ -52,8 +63,30 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var ingressHost string
+	var exposure string
+	var gateway string
+	var cpuRequest, memoryRequest, cpuLimit, memoryLimit string
+	var pullSecret string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&ingressHost, "default-ingress-host", "{{.Name}}.{{.Namespace}}.127.0.0.1.sslip.io",
+		"Template of the Ingress host of the instances which do not set any, e.g. {{.Name}}.{{.Namespace}}.apps.example.com.")
+	flag.StringVar(&exposure, "default-exposure", string(apiv1alpha1.ExposureIngress),
+		"Exposure mode of the instances which do not set any, one of Ingress, Gateway or None.")
+	flag.StringVar(&gateway, "default-gateway", "",
+		"Gateway the routes are attached to when the instances do not set any, as namespace/name.")
+	flag.StringVar(&cpuRequest, "default-cpu-request", "100m",
+		"CPU request of the instances which do not set any, empty for none.")
+	flag.StringVar(&memoryRequest, "default-memory-request", "128Mi",
+		"Memory request of the instances which do not set any, empty for none.")
+	flag.StringVar(&cpuLimit, "default-cpu-limit", "",
+		"CPU limit of the instances which do not set any, empty for none.")
+	flag.StringVar(&memoryLimit, "default-memory-limit", "",
+		"Memory limit of the instances which do not set any, empty for none.")
+	flag.StringVar(&pullSecret, "default-pull-secret", "",
+		"Pull secret added to every instance as namespace/name, usually in the operator namespace. "+
+			"It is copied into the namespaces of the instances and kept in sync.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -65,6 +98,44 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	ingressHostTemplate, err := template.New("ingress-host").Parse(ingressHost)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the default Ingress host template")
+		os.Exit(1)
+	}
+
+	defaultExposure := apiv1alpha1.ExposureMode(exposure)
+	switch defaultExposure {
+	case apiv1alpha1.ExposureIngress, apiv1alpha1.ExposureGateway, apiv1alpha1.ExposureNone:
+	default:
+		setupLog.Error(fmt.Errorf("unknown exposure mode %q", exposure), "invalid default exposure mode")
+		os.Exit(1)
+	}
+
+	var defaultGateway *apiv1alpha1.GatewayParentRef
+	if gateway != "" {
+		defaultGateway = &apiv1alpha1.GatewayParentRef{Name: gateway}
+		if namespace, name, found := strings.Cut(gateway, "/"); found {
+			defaultGateway = &apiv1alpha1.GatewayParentRef{Name: name, Namespace: namespace}
+		}
+	}
+
+	var defaultPullSecret *types.NamespacedName
+	if pullSecret != "" {
+		namespace, name, found := strings.Cut(pullSecret, "/")
+		if !found || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("%q is not of the form namespace/name", pullSecret), "invalid default pull secret")
+			os.Exit(1)
+		}
+		defaultPullSecret = &types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	defaultResources, err := parseDefaultResources(cpuRequest, memoryRequest, cpuLimit, memoryLimit)
+	if err != nil {
+		setupLog.Error(err, "invalid default resources")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -92,6 +163,13 @@ func main() {
 	if err = (&controllers.PodInstanciatorReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+
+		IngressHostTemplate: ingressHostTemplate,
+		DefaultExposure:     defaultExposure,
+		DefaultGateway:      defaultGateway,
+		DefaultResources:    defaultResources,
+		DefaultPullSecret:   defaultPullSecret,
+		Recorder:            mgr.GetEventRecorderFor("podinstanciator"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PodInstanciator")
 		os.Exit(1)
@@ -113,3 +191,30 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parseDefaultResources builds the default requests and limits of the
+// containers from the flags, skipping the empty ones.
+func parseDefaultResources(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) (corev1.ResourceRequirements, error) {
+	resources := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	quantities := []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{resources.Requests, corev1.ResourceCPU, cpuRequest},
+		{resources.Requests, corev1.ResourceMemory, memoryRequest},
+		{resources.Limits, corev1.ResourceCPU, cpuLimit},
+		{resources.Limits, corev1.ResourceMemory, memoryLimit},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return resources, fmt.Errorf("invalid %s quantity %q: %w", q.name, q.value, err)
+		}
+		q.list[q.name] = quantity
+	}
+	return resources, nil
+}